@@ -0,0 +1,14 @@
+CREATE TABLE event_log (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY,
+  time TIMESTAMP WITH TIME ZONE NOT NULL,
+  beginstring VARCHAR2(8) NOT NULL,
+  sendercompid VARCHAR2(64) NOT NULL,
+  sendersubid VARCHAR2(64) NOT NULL,
+  senderlocid VARCHAR2(64) NOT NULL,
+  targetcompid VARCHAR2(64) NOT NULL,
+  targetsubid VARCHAR2(64) NOT NULL,
+  targetlocid VARCHAR2(64) NOT NULL,
+  session_qualifier VARCHAR2(64),
+  text CLOB NOT NULL,
+  PRIMARY KEY (id)
+);
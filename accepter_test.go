@@ -68,3 +68,92 @@ func TestAcceptor_Start(t *testing.T) {
 		})
 	}
 }
+
+func TestAcceptor_AddRemoveSession(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5002")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIX42)
+	sessionSettings.Set(config.SenderCompID, "acceptor")
+	sessionSettings.Set(config.TargetCompID, "static")
+	staticSessionID, err := settings.AddSession(sessionSettings)
+	assert.Nil(t, err)
+
+	acceptor, err := NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	assert.Nil(t, err)
+	assert.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	addedSessionID := SessionID{BeginString: BeginStringFIX42, SenderCompID: "acceptor", TargetCompID: "added"}
+	addedSettings := NewSessionSettings()
+	addedSettings.Set(config.SocketAcceptPort, "5003")
+	assert.Nil(t, acceptor.AddSession(addedSessionID, addedSettings))
+
+	assert.Len(t, acceptor.listeners, 2)
+	assert.Contains(t, settings.SessionSettings(), addedSessionID)
+	added, ok := LookupSession(addedSessionID)
+	assert.True(t, ok)
+	_, err = added.Status()
+	assert.Nil(t, err, "added session should be running")
+
+	conn, err := net.Dial("tcp", "localhost:5003")
+	assert.Nil(t, err, "added session port should be listening")
+	if conn != nil {
+		conn.Close()
+	}
+
+	assert.Equal(t, errDuplicateSessionID, acceptor.AddSession(addedSessionID, addedSettings))
+
+	assert.Nil(t, acceptor.RemoveSession(addedSessionID))
+	assert.Len(t, acceptor.listeners, 1)
+	assert.NotContains(t, settings.SessionSettings(), addedSessionID)
+	_, ok = LookupSession(addedSessionID)
+	assert.False(t, ok)
+	assert.Equal(t, errUnknownSession, acceptor.RemoveSession(addedSessionID))
+
+	_, err = net.Dial("tcp", "localhost:5003")
+	assert.NotNil(t, err, "removed session port should be closed")
+
+	static, ok := LookupSession(staticSessionID)
+	assert.True(t, ok)
+	_, err = static.Status()
+	assert.Nil(t, err, "other sessions should be unaffected")
+}
+
+func TestAcceptor_AddSessionBeforeStart(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5004")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+
+	acceptor, err := NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	assert.Nil(t, err)
+
+	sessionID := SessionID{BeginString: BeginStringFIX42, SenderCompID: "acceptor", TargetCompID: "beforestart"}
+	assert.Nil(t, acceptor.AddSession(sessionID, nil))
+	assert.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	assert.Len(t, acceptor.listeners, 1)
+	session, ok := LookupSession(sessionID)
+	assert.True(t, ok)
+	_, err = session.Status()
+	assert.Nil(t, err)
+}
+
+func TestAcceptor_AddSessionMismatchedSettings(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5005")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+
+	acceptor, err := NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	assert.Nil(t, err)
+
+	sessionID := SessionID{BeginString: BeginStringFIX42, SenderCompID: "acceptor", TargetCompID: "mismatch"}
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.SenderSubID, "desk")
+	assert.NotNil(t, acceptor.AddSession(sessionID, sessionSettings))
+	assert.Empty(t, settings.SessionSettings())
+	assert.Empty(t, acceptor.sessions)
+}
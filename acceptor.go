@@ -3,7 +3,9 @@ package quickfix
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
 	"runtime/debug"
@@ -22,6 +24,8 @@ type Acceptor struct {
 	storeFactory          MessageStoreFactory
 	globalLog             Log
 	sessions              map[SessionID]*session
+	sessionsMutex         sync.RWMutex
+	reloadMutex           sync.Mutex
 	sessionGroup          sync.WaitGroup
 	listenerShutdown      sync.WaitGroup
 	dynamicSessions       bool
@@ -29,8 +33,13 @@ type Acceptor struct {
 	dynamicQualifierCount int
 	dynamicSessionChan    chan *session
 	sessionAddr           sync.Map
+	sessionConns          sync.Map
 	sessionHostPort       map[SessionID]int
 	listeners             map[string]net.Listener
+	socketAcceptHost      string
+	tlsConfig             *tls.Config
+	useTCPProxy           bool
+	started               bool
 	connectionValidator   ConnectionValidator
 	sessionFactory
 }
@@ -44,9 +53,18 @@ type ConnectionValidator interface {
 
 //Start accepting connections.
 func (a *Acceptor) Start() (err error) {
-	socketAcceptHost := ""
+	return a.StartContext(context.Background())
+}
+
+//StartContext starts accepting connections. The context bounds starting only: if it is done before the Acceptor has
+//started, listeners already opened are closed and the context error is returned. Use StopContext to stop.
+func (a *Acceptor) StartContext(ctx context.Context) (err error) {
+	a.sessionsMutex.Lock()
+	defer a.sessionsMutex.Unlock()
+
+	a.socketAcceptHost = ""
 	if a.settings.GlobalSettings().HasSetting(config.SocketAcceptHost) {
-		if socketAcceptHost, err = a.settings.GlobalSettings().Setting(config.SocketAcceptHost); err != nil {
+		if a.socketAcceptHost, err = a.settings.GlobalSettings().Setting(config.SocketAcceptHost); err != nil {
 			return
 		}
 	}
@@ -54,47 +72,46 @@ func (a *Acceptor) Start() (err error) {
 	a.sessionHostPort = make(map[SessionID]int)
 	a.listeners = make(map[string]net.Listener)
 	for sessionID, sessionSettings := range a.settings.SessionSettings() {
-		if sessionSettings.HasSetting(config.SocketAcceptPort) {
-			if a.sessionHostPort[sessionID], err = sessionSettings.IntSetting(config.SocketAcceptPort); err != nil {
-				return
-			}
-		} else if a.sessionHostPort[sessionID], err = a.settings.GlobalSettings().IntSetting(config.SocketAcceptPort); err != nil {
+		var address string
+		if address, err = a.configureAcceptPort(sessionID, sessionSettings); err != nil {
 			return
 		}
-		address := net.JoinHostPort(socketAcceptHost, strconv.Itoa(a.sessionHostPort[sessionID]))
 		a.listeners[address] = nil
 	}
 
-	var tlsConfig *tls.Config
-	if tlsConfig, err = loadTLSConfig(a.settings.GlobalSettings()); err != nil {
+	if a.tlsConfig, err = loadTLSConfig(a.settings.GlobalSettings()); err != nil {
 		return
 	}
 
-	var useTCPProxy bool
+	a.useTCPProxy = false
 	if a.settings.GlobalSettings().HasSetting(config.UseTCPProxy) {
-		if useTCPProxy, err = a.settings.GlobalSettings().BoolSetting(config.UseTCPProxy); err != nil {
+		if a.useTCPProxy, err = a.settings.GlobalSettings().BoolSetting(config.UseTCPProxy); err != nil {
 			return
 		}
 	}
 
-	for address := range a.listeners {
-		if tlsConfig != nil {
-			if a.listeners[address], err = tls.Listen("tcp", address, tlsConfig); err != nil {
-				return
+	defer func() {
+		if err != nil {
+			for _, listener := range a.listeners {
+				if listener != nil {
+					listener.Close()
+				}
 			}
-		} else if a.listeners[address], err = net.Listen("tcp", address); err != nil {
+		}
+	}()
+
+	for address := range a.listeners {
+		if a.listeners[address], err = a.listen(ctx, address); err != nil {
 			return
-		} else if useTCPProxy {
-			a.listeners[address] = &proxyproto.Listener{Listener: a.listeners[address]}
 		}
 	}
 
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	for _, s := range a.sessions {
-		a.sessionGroup.Add(1)
-		go func(s *session) {
-			s.run()
-			a.sessionGroup.Done()
-		}(s)
+		a.runSession(s)
 	}
 	if a.dynamicSessions {
 		a.dynamicSessionChan = make(chan *session)
@@ -108,26 +125,228 @@ func (a *Acceptor) Start() (err error) {
 	for _, listener := range a.listeners {
 		go a.listenForConnections(listener)
 	}
+	a.started = true
 	return
 }
 
 //Stop logs out existing sessions, close their connections, and stop accepting new connections.
 func (a *Acceptor) Stop() {
+	_ = a.StopContext(context.Background())
+}
+
+//StopContext stops accepting new connections and logs out existing sessions. Messages queued for logged on sessions
+//are sent ahead of the Logout. Sessions wait for their Logout to be acknowledged until the ctx deadline, after which
+//their connections are forced closed. The sessions that could not be stopped gracefully are reported as SessionErrors.
+func (a *Acceptor) StopContext(ctx context.Context) (err error) {
 	defer func() {
 		_ = recover() // suppress sending on closed channel error
 	}()
 
+	a.sessionsMutex.Lock()
+	a.started = false
 	for _, listener := range a.listeners {
-		listener.Close()
+		if listener != nil {
+			listener.Close()
+		}
 	}
+	a.sessionsMutex.Unlock()
+
 	a.listenerShutdown.Wait()
 	if a.dynamicSessions {
 		close(a.dynamicSessionChan)
 	}
+
+	a.sessionsMutex.RLock()
+	sessions := make([]*session, 0, len(a.sessions))
 	for _, session := range a.sessions {
-		session.stop()
+		sessions = append(sessions, session)
 	}
-	a.sessionGroup.Wait()
+	a.sessionsMutex.RUnlock()
+
+	errs := stopSessions(ctx, sessions, &a.sessionConns)
+	if !waitContext(ctx, &a.sessionGroup) {
+		closeConnections(&a.sessionConns)
+		a.sessionGroup.Wait()
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//AddSession creates a session with the given settings, overlaid on the global settings of the Acceptor. If the
+//Acceptor is started, the session is started and a listener is opened if the session's SocketAcceptPort is not in
+//use yet. The settings are also added to the Settings the Acceptor was created with, so that store and log factories
+//created with the same Settings know about the session.
+func (a *Acceptor) AddSession(sessionID SessionID, sessionSettings *SessionSettings) (err error) {
+	a.sessionsMutex.Lock()
+	defer a.sessionsMutex.Unlock()
+
+	sessID := sessionID
+	sessID.Qualifier = ""
+	if _, dup := a.sessions[sessID]; dup {
+		return errDuplicateSessionID
+	}
+
+	if err = a.settings.addSessionWithID(sessionID, sessionSettings); err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			a.settings.removeSession(sessionID)
+			delete(a.sessionHostPort, sessID)
+		}
+	}()
+
+	allSettings := a.settings.SessionSettings()[sessionID]
+	var address string
+	if address, err = a.configureAcceptPort(sessionID, allSettings); err != nil {
+		return
+	}
+
+	var s *session
+	if s, err = a.createSession(sessionID, a.storeFactory, allSettings, a.logFactory, a.app); err != nil {
+		return
+	}
+
+	if a.started {
+		if _, ok := a.listeners[address]; !ok {
+			var listener net.Listener
+			if listener, err = a.listen(context.Background(), address); err != nil {
+				_ = UnregisterSession(sessionID)
+				_ = s.store.Close()
+				return
+			}
+			a.listeners[address] = listener
+			a.listenerShutdown.Add(1)
+			go a.listenForConnections(listener)
+		}
+		a.runSession(s)
+	}
+
+	a.sessions[sessID] = s
+	return
+}
+
+//RemoveSession logs out and stops the session, closes its message store and stops listening on its SocketAcceptPort
+//if no other session uses it. Other sessions are unaffected.
+func (a *Acceptor) RemoveSession(sessionID SessionID) error {
+	sessID := sessionID
+	sessID.Qualifier = ""
+
+	a.sessionsMutex.Lock()
+	s, ok := a.sessions[sessID]
+	if !ok {
+		a.sessionsMutex.Unlock()
+		return errUnknownSession
+	}
+	delete(a.sessions, sessID)
+	port := a.sessionHostPort[sessID]
+	delete(a.sessionHostPort, sessID)
+	a.settings.removeSession(s.sessionID)
+
+	address := net.JoinHostPort(a.socketAcceptHost, strconv.Itoa(port))
+	if listener, ok := a.listeners[address]; ok && !a.portInUse(port) {
+		delete(a.listeners, address)
+		if listener != nil {
+			listener.Close()
+		}
+	}
+	a.sessionsMutex.Unlock()
+
+	s.stopAndWait()
+	a.sessionAddr.Delete(sessID)
+
+	if err := UnregisterSession(s.sessionID); err != nil {
+		return err
+	}
+
+	closeLog(s.log)
+	return s.store.Close()
+}
+
+//Reload applies updated settings, e.g. parsed from an edited configuration file. Sessions are compared with the
+//current settings: removed sessions are logged out and removed, added sessions are added. Changes to the schedule,
+//HeartBtInt, MaxLatency, validation flags and log and store paths are applied without interrupting the session;
+//a changed HeartBtInt takes effect from the next logon. Sessions with other changed settings are restarted.
+//Settings that apply to all sessions of the Acceptor, such as SocketAcceptHost or TLS, cannot be reloaded and result
+//in an error without any changes being applied. Errors for individual sessions are reported as SessionErrors.
+func (a *Acceptor) Reload(settings *Settings) (SettingsDiff, error) {
+	a.reloadMutex.Lock()
+	defer a.reloadMutex.Unlock()
+
+	if err := checkGlobalSettings(a.settings, settings, acceptorSettings); err != nil {
+		return SettingsDiff{}, err
+	}
+
+	return reloadSettings(a.settings, settings, a.RemoveSession, a.AddSession, a.reloadSession)
+}
+
+func (a *Acceptor) reloadSession(sessionID SessionID, old, new *SessionSettings) error {
+	a.sessionsMutex.RLock()
+	defer a.sessionsMutex.RUnlock()
+
+	sessID := sessionID
+	sessID.Qualifier = ""
+	s, ok := a.sessions[sessID]
+	if !ok {
+		return errUnknownSession
+	}
+
+	return a.sessionFactory.reloadSession(s, old, new, a.storeFactory, a.logFactory)
+}
+
+//configureAcceptPort records the SocketAcceptPort of the session and returns the address to listen on
+func (a *Acceptor) configureAcceptPort(sessionID SessionID, sessionSettings *SessionSettings) (address string, err error) {
+	var port int
+	if sessionSettings.HasSetting(config.SocketAcceptPort) {
+		if port, err = sessionSettings.IntSetting(config.SocketAcceptPort); err != nil {
+			return
+		}
+	} else if port, err = a.settings.GlobalSettings().IntSetting(config.SocketAcceptPort); err != nil {
+		return
+	}
+
+	sessID := sessionID
+	sessID.Qualifier = ""
+	a.sessionHostPort[sessID] = port
+	return net.JoinHostPort(a.socketAcceptHost, strconv.Itoa(port)), nil
+}
+
+func (a *Acceptor) portInUse(port int) bool {
+	for _, p := range a.sessionHostPort {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Acceptor) listen(ctx context.Context, address string) (listener net.Listener, err error) {
+	var listenConfig net.ListenConfig
+	if listener, err = listenConfig.Listen(ctx, "tcp", address); err != nil {
+		return
+	}
+
+	if a.tlsConfig != nil {
+		return tls.NewListener(listener, a.tlsConfig), nil
+	}
+
+	if a.useTCPProxy {
+		listener = &proxyproto.Listener{Listener: listener}
+	}
+	return
+}
+
+func (a *Acceptor) runSession(s *session) {
+	s.markRunning()
+	a.sessionGroup.Add(1)
+	go func() {
+		s.run()
+		a.sessionGroup.Done()
+	}()
 }
 
 //Get remote IP address for a given session.
@@ -140,6 +359,17 @@ func (a *Acceptor) RemoteAddr(sessionID SessionID) (net.Addr, bool) {
 	return val, ok
 }
 
+func (a *Acceptor) sessionIDs() []SessionID {
+	a.sessionsMutex.RLock()
+	defer a.sessionsMutex.RUnlock()
+
+	sessionIDs := make([]SessionID, 0, len(a.sessions))
+	for _, session := range a.sessions {
+		sessionIDs = append(sessionIDs, session.sessionID)
+	}
+	return sessionIDs
+}
+
 //NewAcceptor creates and initializes a new Acceptor.
 func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Settings, logFactory LogFactory) (a *Acceptor, err error) {
 	a = &Acceptor{
@@ -199,13 +429,15 @@ func (a *Acceptor) listenForConnections(listener net.Listener) {
 }
 
 func (a *Acceptor) invalidMessage(msg *bytes.Buffer, err error) {
-	a.globalLog.OnEventf("Invalid Message: %s, %v", msg.Bytes(), err.Error())
+	logEvent(a.globalLog, LogEvent{Level: LogLevelError, Code: LogCodeInvalidMessage,
+		Message: fmt.Sprintf("Invalid Message: %s, %v", msg.Bytes(), err.Error()), Attrs: []LogAttr{{"error", err}}})
 }
 
 func (a *Acceptor) handleConnection(netConn net.Conn) {
 	defer func() {
 		if err := recover(); err != nil {
-			a.globalLog.OnEventf("Connection Terminated with Panic: %s", debug.Stack())
+			logEvent(a.globalLog, LogEvent{Level: LogLevelError, Code: LogCodeConnectionTerminated,
+				Message: fmt.Sprintf("Connection Terminated with Panic: %s", debug.Stack())})
 		}
 
 		if err := netConn.Close(); err != nil {
@@ -288,8 +520,12 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 		TargetCompID: string(senderCompID), TargetSubID: string(senderSubID), TargetLocationID: string(senderLocationID),
 	}
 
+	a.sessionsMutex.RLock()
+	expectedPort, ok := a.sessionHostPort[sessID]
+	a.sessionsMutex.RUnlock()
+
 	localConnectionPort := netConn.LocalAddr().(*net.TCPAddr).Port
-	if expectedPort, ok := a.sessionHostPort[sessID]; !ok || expectedPort != localConnectionPort {
+	if !ok || expectedPort != localConnectionPort {
 		a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
 		return
 	}
@@ -306,7 +542,9 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 		a.dynamicQualifierCount++
 		sessID.Qualifier = strconv.Itoa(a.dynamicQualifierCount)
 	}
+	a.sessionsMutex.RLock()
 	session, ok := a.sessions[sessID]
+	a.sessionsMutex.RUnlock()
 	if !ok {
 		if !a.dynamicSessions {
 			a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
@@ -317,6 +555,8 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 			a.globalLog.OnEventf("Dynamic session %v failed to create: %v", sessID, err)
 			return
 		}
+		//marked running ahead of the hand-off, so connect below is queued for the run goroutine of dynamicSessionsLoop
+		dynamicSession.markRunning()
 		a.dynamicSessionChan <- dynamicSession
 		session = dynamicSession
 		defer session.stop()
@@ -324,13 +564,17 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 
 	a.sessionAddr.Store(sessID, netConn.RemoteAddr())
 	msgIn := make(chan fixIn)
-	msgOut := make(chan []byte)
+	msgOut := make(chan fixOut)
 
 	if err := session.connect(msgIn, msgOut); err != nil {
 		a.globalLog.OnEventf("Unable to accept %v", err.Error())
 		return
 	}
 
+	session.notify(ConnectedEvent{RemoteAddr: netConn.RemoteAddr()})
+	a.sessionConns.Store(session, netConn)
+	defer a.sessionConns.Delete(session)
+
 	go func() {
 		msgIn <- fixIn{msgBytes, parser.lastRead}
 		readLoop(parser, msgIn)
@@ -0,0 +1,276 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+//adminSession is the JSON representation of a session served by the admin handler
+type adminSession struct {
+	SessionID           string    `json:"session_id"`
+	State               string    `json:"state,omitempty"`
+	Connected           bool      `json:"connected"`
+	LoggedOn            bool      `json:"logged_on"`
+	SessionTime         bool      `json:"session_time"`
+	NextSenderMsgSeqNum int       `json:"next_sender_msg_seq_num"`
+	NextTargetMsgSeqNum int       `json:"next_target_msg_seq_num"`
+	CreationTime        time.Time `json:"creation_time"`
+	RemoteAddr          string    `json:"remote_addr,omitempty"`
+	Error               string    `json:"error,omitempty"`
+}
+
+type adminLogoutRequest struct {
+	Reason string `json:"reason"`
+}
+
+type adminSeqNumsRequest struct {
+	NextSenderMsgSeqNum int  `json:"next_sender_msg_seq_num"`
+	NextTargetMsgSeqNum int  `json:"next_target_msg_seq_num"`
+	SequenceReset       bool `json:"sequence_reset"`
+	ResendRequest       bool `json:"resend_request"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+type adminHandler struct {
+	sessionIDs func() []SessionID
+	remoteAddr func(SessionID) (net.Addr, bool)
+	mux        *http.ServeMux
+}
+
+//AdminHandler returns an http.Handler to inspect and control the sessions of the Acceptor.
+//See NewAdminHandler for the endpoints served.
+func (a *Acceptor) AdminHandler() http.Handler {
+	return newAdminHandler(a.sessionIDs, func(sessionID SessionID) (net.Addr, bool) {
+		//connections are tracked without the session qualifier
+		sessionID.Qualifier = ""
+		return a.RemoteAddr(sessionID)
+	})
+}
+
+//AdminHandler returns an http.Handler to inspect and control the sessions of the Initiator.
+//See NewAdminHandler for the endpoints served.
+func (i *Initiator) AdminHandler() http.Handler {
+	return newAdminHandler(i.sessionIDs, i.RemoteAddr)
+}
+
+//NewAdminHandler returns an http.Handler to inspect and control all registered sessions. The handler may be mounted in
+//an application's own server, e.g. with http.StripPrefix. Sessions are identified by the id query parameter, the value
+//of SessionID.String(). Responses are JSON. The following endpoints are served:
+//
+//  GET  /sessions                    lists all sessions with their state, sequence numbers and remote address
+//  GET  /session?id=<id>             returns a single session
+//  POST /session/logon?id=<id>       connects an initiator session without waiting for the reconnect interval
+//  POST /session/logout?id=<id>      logs out the session, optional body {"reason": "..."}
+//  POST /session/disconnect?id=<id>  drops the connection without logging out
+//  POST /session/reset?id=<id>       logs out and resets sequence numbers to 1
+//  POST /session/refresh?id=<id>     reloads the session state from the message store
+//  POST /session/seqnums?id=<id>     sets sequence numbers, body {"next_sender_msg_seq_num": n, "sequence_reset": true,
+//                                    "next_target_msg_seq_num": n, "resend_request": true}, zero values are left unchanged
+func NewAdminHandler() http.Handler {
+	return newAdminHandler(registeredSessionIDs, nil)
+}
+
+func registeredSessionIDs() []SessionID {
+	sessionsLock.RLock()
+	defer sessionsLock.RUnlock()
+
+	sessionIDs := make([]SessionID, 0, len(sessions))
+	for sessionID := range sessions {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	return sessionIDs
+}
+
+func newAdminHandler(sessionIDs func() []SessionID, remoteAddr func(SessionID) (net.Addr, bool)) *adminHandler {
+	h := &adminHandler{
+		sessionIDs: sessionIDs,
+		remoteAddr: remoteAddr,
+		mux:        http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("/sessions", h.get(h.handleSessions))
+	h.mux.HandleFunc("/session", h.get(h.withSession(h.handleSession)))
+	h.mux.HandleFunc("/session/logon", h.post(h.withSession(h.handleLogon)))
+	h.mux.HandleFunc("/session/logout", h.post(h.withSession(h.handleLogout)))
+	h.mux.HandleFunc("/session/disconnect", h.post(h.withSession(h.handleDisconnect)))
+	h.mux.HandleFunc("/session/reset", h.post(h.withSession(h.handleReset)))
+	h.mux.HandleFunc("/session/refresh", h.post(h.withSession(h.handleRefresh)))
+	h.mux.HandleFunc("/session/seqnums", h.post(h.withSession(h.handleSeqNums)))
+
+	return h
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *adminHandler) get(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *adminHandler) post(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeAdminError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method))
+			return
+		}
+		next(w, r)
+	}
+}
+
+type adminSessionHandlerFunc func(http.ResponseWriter, *http.Request, *Session)
+
+//withSession resolves the session named by the id query parameter among the sessions served by the handler
+func (h *adminHandler) withSession(next adminSessionHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("missing session id"))
+			return
+		}
+
+		for _, sessionID := range h.sessionIDs() {
+			if sessionID.String() != id {
+				continue
+			}
+
+			if session, ok := LookupSession(sessionID); ok {
+				next(w, r, session)
+				return
+			}
+		}
+
+		writeAdminError(w, http.StatusNotFound, fmt.Errorf("unknown session: %v", id))
+	}
+}
+
+func (h *adminHandler) sessionView(session *Session) adminSession {
+	view := adminSession{SessionID: session.SessionID().String()}
+
+	status, err := session.Status()
+	if err != nil {
+		view.Error = err.Error()
+	} else {
+		view.State = status.State
+		view.Connected = status.IsConnected
+		view.LoggedOn = status.IsLoggedOn
+		view.SessionTime = status.IsSessionTime
+		view.NextSenderMsgSeqNum = status.NextSenderMsgSeqNum
+		view.NextTargetMsgSeqNum = status.NextTargetMsgSeqNum
+		view.CreationTime = status.CreationTime
+	}
+
+	if h.remoteAddr != nil {
+		if addr, ok := h.remoteAddr(session.SessionID()); ok {
+			view.RemoteAddr = addr.String()
+		}
+	}
+
+	return view
+}
+
+func (h *adminHandler) handleSessions(w http.ResponseWriter, r *http.Request) {
+	sessionIDs := h.sessionIDs()
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	views := make([]adminSession, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		if session, ok := LookupSession(sessionID); ok {
+			views = append(views, h.sessionView(session))
+		}
+	}
+
+	writeAdminJSON(w, http.StatusOK, views)
+}
+
+func (h *adminHandler) handleSession(w http.ResponseWriter, r *http.Request, session *Session) {
+	writeAdminJSON(w, http.StatusOK, h.sessionView(session))
+}
+
+func (h *adminHandler) handleLogon(w http.ResponseWriter, r *http.Request, session *Session) {
+	h.writeResult(w, session, session.Logon())
+}
+
+func (h *adminHandler) handleLogout(w http.ResponseWriter, r *http.Request, session *Session) {
+	var req adminLogoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	h.writeResult(w, session, session.Logout(req.Reason))
+}
+
+func (h *adminHandler) handleDisconnect(w http.ResponseWriter, r *http.Request, session *Session) {
+	h.writeResult(w, session, session.Disconnect())
+}
+
+func (h *adminHandler) handleReset(w http.ResponseWriter, r *http.Request, session *Session) {
+	h.writeResult(w, session, session.Reset())
+}
+
+func (h *adminHandler) handleRefresh(w http.ResponseWriter, r *http.Request, session *Session) {
+	h.writeResult(w, session, session.Refresh())
+}
+
+func (h *adminHandler) handleSeqNums(w http.ResponseWriter, r *http.Request, session *Session) {
+	var req adminSeqNumsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.NextSenderMsgSeqNum < 0 || req.NextTargetMsgSeqNum < 0 {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("sequence numbers must be positive"))
+		return
+	}
+
+	if req.NextSenderMsgSeqNum > 0 {
+		if err := session.SetNextSenderMsgSeqNum(req.NextSenderMsgSeqNum, req.SequenceReset); err != nil {
+			h.writeResult(w, session, err)
+			return
+		}
+	}
+
+	var err error
+	if req.NextTargetMsgSeqNum > 0 {
+		err = session.SetNextTargetMsgSeqNum(req.NextTargetMsgSeqNum, req.ResendRequest)
+	}
+
+	h.writeResult(w, session, err)
+}
+
+//writeResult responds with the current session state, or a conflict if the requested operation failed
+func (h *adminHandler) writeResult(w http.ResponseWriter, session *Session, err error) {
+	if err != nil {
+		writeAdminError(w, http.StatusConflict, err)
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, h.sessionView(session))
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, adminError{Error: err.Error()})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,180 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminHandlerTestSuite struct {
+	SessionSuiteRig
+	handler  http.Handler
+	sessDone chan interface{}
+}
+
+func TestAdminHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
+
+func (suite *AdminHandlerTestSuite) SetupTest() {
+	suite.Init()
+	suite.session.admin = make(chan interface{})
+	suite.session.messageEvent = make(chan bool, 1)
+	suite.session.connectNow = make(chan struct{}, 1)
+
+	sessionsLock.Lock()
+	sessions = make(map[SessionID]*session)
+	sessionsLock.Unlock()
+	suite.Require().Nil(registerSession(suite.session))
+
+	remoteAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5001}
+	suite.handler = newAdminHandler(
+		func() []SessionID { return []SessionID{suite.session.sessionID} },
+		func(SessionID) (net.Addr, bool) { return remoteAddr, true },
+	)
+}
+
+func (suite *AdminHandlerTestSuite) TearDownTest() {
+	if suite.sessDone != nil {
+		suite.session.stop()
+		<-suite.sessDone
+		suite.sessDone = nil
+	}
+}
+
+func (suite *AdminHandlerTestSuite) givenARunningSession() {
+	suite.sessDone = make(chan interface{})
+	go func() {
+		suite.session.run()
+		close(suite.sessDone)
+	}()
+
+	handle := &Session{session: suite.session}
+	for _, err := handle.Status(); err != nil; _, err = handle.Status() {
+	}
+}
+
+func (suite *AdminHandlerTestSuite) request(method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	suite.Equal("application/json", rec.Header().Get("Content-Type"))
+	return rec
+}
+
+func (suite *AdminHandlerTestSuite) sessionTarget(path string) string {
+	return path + "?id=" + url.QueryEscape(suite.session.sessionID.String())
+}
+
+func (suite *AdminHandlerTestSuite) decode(rec *httptest.ResponseRecorder, v interface{}) {
+	suite.Require().Nil(json.NewDecoder(rec.Body).Decode(v))
+}
+
+func (suite *AdminHandlerTestSuite) TestListSessions() {
+	suite.givenARunningSession()
+
+	rec := suite.request(http.MethodGet, "/sessions", "")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var views []adminSession
+	suite.decode(rec, &views)
+	suite.Require().Len(views, 1)
+	suite.Equal(suite.session.sessionID.String(), views[0].SessionID)
+	suite.Equal("Latent State", views[0].State)
+	suite.False(views[0].Connected)
+	suite.True(views[0].SessionTime)
+	suite.Equal(1, views[0].NextSenderMsgSeqNum)
+	suite.Equal(1, views[0].NextTargetMsgSeqNum)
+	suite.Equal("127.0.0.1:5001", views[0].RemoteAddr)
+	suite.Empty(views[0].Error)
+}
+
+func (suite *AdminHandlerTestSuite) TestSessionNotRunning() {
+	rec := suite.request(http.MethodGet, suite.sessionTarget("/session"), "")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var view adminSession
+	suite.decode(rec, &view)
+	suite.Equal(errSessionNotRunning.Error(), view.Error)
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/reset"), "")
+	suite.Equal(http.StatusConflict, rec.Code)
+}
+
+func (suite *AdminHandlerTestSuite) TestUnknownSession() {
+	rec := suite.request(http.MethodGet, "/session?id=FIX.4.2:A->B", "")
+	suite.Equal(http.StatusNotFound, rec.Code)
+
+	rec = suite.request(http.MethodGet, "/session", "")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *AdminHandlerTestSuite) TestMethodNotAllowed() {
+	rec := suite.request(http.MethodPost, "/sessions", "")
+	suite.Equal(http.StatusMethodNotAllowed, rec.Code)
+
+	rec = suite.request(http.MethodGet, suite.sessionTarget("/session/reset"), "")
+	suite.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func (suite *AdminHandlerTestSuite) TestSeqNums() {
+	suite.givenARunningSession()
+
+	rec := suite.request(http.MethodPost, suite.sessionTarget("/session/seqnums"),
+		`{"next_sender_msg_seq_num": 10, "next_target_msg_seq_num": 20}`)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var view adminSession
+	suite.decode(rec, &view)
+	suite.Equal(10, view.NextSenderMsgSeqNum)
+	suite.Equal(20, view.NextTargetMsgSeqNum)
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/seqnums"), `{"next_sender_msg_seq_num": -1}`)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/seqnums"),
+		`{"next_sender_msg_seq_num": 30, "sequence_reset": true}`)
+	suite.Equal(http.StatusConflict, rec.Code, "session is not logged on")
+}
+
+func (suite *AdminHandlerTestSuite) TestResetAndRefresh() {
+	suite.givenARunningSession()
+	suite.MockStore.On("Refresh").Return(nil)
+
+	rec := suite.request(http.MethodPost, suite.sessionTarget("/session/seqnums"), `{"next_sender_msg_seq_num": 10}`)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/reset"), "")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var view adminSession
+	suite.decode(rec, &view)
+	suite.Equal(1, view.NextSenderMsgSeqNum)
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/refresh"), "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.MockStore.AssertExpectations(suite.T())
+}
+
+func (suite *AdminHandlerTestSuite) TestLogonLogoutDisconnect() {
+	suite.givenARunningSession()
+
+	rec := suite.request(http.MethodPost, suite.sessionTarget("/session/logout"), `{"reason": "maintenance"}`)
+	suite.Equal(http.StatusConflict, rec.Code, "session is not logged on")
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/disconnect"), "")
+	suite.Equal(http.StatusConflict, rec.Code, "session is not connected")
+
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/logon"), "")
+	suite.Equal(http.StatusConflict, rec.Code, "acceptors cannot initiate logon")
+
+	suite.session.InitiateLogon = true
+	rec = suite.request(http.MethodPost, suite.sessionTarget("/session/logon"), "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Len(suite.session.connectNow, 1)
+}
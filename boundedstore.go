@@ -0,0 +1,144 @@
+package quickfix
+
+import (
+	"github.com/pkg/errors"
+)
+
+type boundedMemoryStoreFactory struct {
+	maxMessages int
+	maxBytes    int
+}
+
+//boundedMessage is a slot of the ring, msg is nil once the seqnum is saved again
+type boundedMessage struct {
+	seqNum int
+	msg    []byte
+}
+
+//boundedMemoryStore is a memoryStore retaining only the most recently saved messages. Messages are kept in a ring in
+//the order they are saved, the oldest are dropped once more than maxMessages or maxBytes are retained.
+type boundedMemoryStore struct {
+	memoryStore
+	maxMessages int
+	maxBytes    int
+
+	ring  []boundedMessage
+	head  int
+	count int
+	bytes int
+
+	//position of the head slot since the last reset, positions of later slots follow on
+	headPos int
+
+	//position of the slot of each retained seqnum
+	positions map[int]int
+}
+
+//NewBoundedMemoryStoreFactory returns a MessageStoreFactory of in-memory MessageStores that retain only the last
+//maxMessages messages, or the last maxBytes bytes of messages, whichever is less. 0 disables the limit. Resends of
+//messages that are no longer retained are gap filled, the seqnums are tracked in full. A message larger than maxBytes
+//is not retained.
+func NewBoundedMemoryStoreFactory(maxMessages, maxBytes int) MessageStoreFactory {
+	return boundedMemoryStoreFactory{maxMessages: maxMessages, maxBytes: maxBytes}
+}
+
+func (f boundedMemoryStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	if f.maxMessages < 0 || f.maxBytes < 0 {
+		return nil, errors.New("bounded memory store limits must not be negative")
+	}
+
+	m := &boundedMemoryStore{maxMessages: f.maxMessages, maxBytes: f.maxBytes}
+	if err := m.Reset(); err != nil {
+		return m, errors.Wrap(err, "reset")
+	}
+	return m, nil
+}
+
+func (store *boundedMemoryStore) Reset() error {
+	if err := store.memoryStore.Reset(); err != nil {
+		return err
+	}
+
+	store.ring = nil
+	store.head, store.count, store.bytes, store.headPos = 0, 0, 0, 0
+	store.positions = make(map[int]int)
+	return nil
+}
+
+func (store *boundedMemoryStore) slot(pos int) *boundedMessage {
+	return &store.ring[(store.head+pos-store.headPos)%len(store.ring)]
+}
+
+//push appends a slot to the ring, doubling the ring when it is full
+func (store *boundedMemoryStore) push(m boundedMessage) int {
+	if store.count == len(store.ring) {
+		ring := make([]boundedMessage, 2*len(store.ring)+1)
+		for i := 0; i < store.count; i++ {
+			ring[i] = store.ring[(store.head+i)%len(store.ring)]
+		}
+		store.ring, store.head = ring, 0
+	}
+
+	pos := store.headPos + store.count
+	store.count++
+	*store.slot(pos) = m
+	return pos
+}
+
+//pop drops the head slot of the ring
+func (store *boundedMemoryStore) pop() {
+	m := store.slot(store.headPos)
+	if m.msg != nil {
+		delete(store.positions, m.seqNum)
+		store.bytes -= len(m.msg)
+	}
+
+	*m = boundedMessage{}
+	store.head = (store.head + 1) % len(store.ring)
+	store.headPos++
+	store.count--
+}
+
+func (store *boundedMemoryStore) overLimit() bool {
+	return (store.maxMessages > 0 && len(store.positions) > store.maxMessages) ||
+		(store.maxBytes > 0 && store.bytes > store.maxBytes)
+}
+
+func (store *boundedMemoryStore) SaveMessage(seqNum int, msg []byte) error {
+	if pos, ok := store.positions[seqNum]; ok {
+		m := store.slot(pos)
+		store.bytes -= len(m.msg)
+		m.msg = nil
+	}
+
+	if msg == nil {
+		msg = []byte{}
+	}
+	store.positions[seqNum] = store.push(boundedMessage{seqNum: seqNum, msg: msg})
+	store.bytes += len(msg)
+
+	for store.count > 0 && (store.overLimit() || store.slot(store.headPos).msg == nil) {
+		store.pop()
+	}
+	return nil
+}
+
+func (store *boundedMemoryStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
+	for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
+		if pos, ok := store.positions[seqNum]; ok {
+			if err := fn(seqNum, store.slot(pos).msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (store *boundedMemoryStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	})
+	return msgs, err
+}
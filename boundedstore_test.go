@@ -0,0 +1,99 @@
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// BoundedMemoryStoreTestSuite runs all tests in the MessageStoreTestSuite against the bounded memory store
+type BoundedMemoryStoreTestSuite struct {
+	MessageStoreTestSuite
+}
+
+func (suite *BoundedMemoryStoreTestSuite) SetupTest() {
+	var err error
+	suite.msgStore, err = NewBoundedMemoryStoreFactory(10, 1024).Create(SessionID{})
+	require.Nil(suite.T(), err)
+}
+
+func TestBoundedMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(BoundedMemoryStoreTestSuite))
+}
+
+func newBoundedMemoryStore(t *testing.T, maxMessages, maxBytes int) *boundedMemoryStore {
+	store, err := NewBoundedMemoryStoreFactory(maxMessages, maxBytes).Create(SessionID{})
+	require.Nil(t, err)
+	return store.(*boundedMemoryStore)
+}
+
+func TestBoundedMemoryStore_MaxMessages(t *testing.T) {
+	store := newBoundedMemoryStore(t, 3, 0)
+
+	for seqNum := 1; seqNum <= 100; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte{byte(seqNum)}))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+
+	msgs, err := store.GetMessages(1, 100)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{{98}, {99}, {100}}, msgs)
+	assert.Equal(t, 101, store.NextSenderMsgSeqNum(), "seqnums should be tracked beyond the retained messages")
+	assert.Len(t, store.positions, 3)
+	assert.LessOrEqual(t, len(store.ring), 7, "the ring should not grow beyond the retained messages")
+}
+
+func TestBoundedMemoryStore_MaxBytes(t *testing.T) {
+	store := newBoundedMemoryStore(t, 0, 10)
+
+	require.Nil(t, store.SaveMessage(1, []byte("aaaa")))
+	require.Nil(t, store.SaveMessage(2, []byte("bbbb")))
+	require.Nil(t, store.SaveMessage(3, []byte("cccc")))
+
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("bbbb"), []byte("cccc")}, msgs)
+	assert.Equal(t, 8, store.bytes)
+
+	require.Nil(t, store.SaveMessage(4, []byte("a message larger than the limit")))
+	msgs, err = store.GetMessages(1, 4)
+	require.Nil(t, err)
+	assert.Empty(t, msgs)
+	assert.Equal(t, 0, store.bytes)
+}
+
+func TestBoundedMemoryStore_SaveMessageAgain(t *testing.T) {
+	store := newBoundedMemoryStore(t, 2, 0)
+
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+	require.Nil(t, store.SaveMessage(2, []byte("two")))
+	require.Nil(t, store.SaveMessage(1, []byte("ONE")))
+
+	msgs, err := store.GetMessages(1, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("ONE"), []byte("two")}, msgs)
+
+	require.Nil(t, store.SaveMessage(3, []byte("three")))
+	msgs, err = store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("ONE"), []byte("three")}, msgs, "the message saved least recently should be dropped")
+}
+
+func TestBoundedMemoryStore_Reset(t *testing.T) {
+	store := newBoundedMemoryStore(t, 2, 0)
+
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+	require.Nil(t, store.SetNextSenderMsgSeqNum(2))
+	require.Nil(t, store.Reset())
+
+	msgs, err := store.GetMessages(1, 1)
+	require.Nil(t, err)
+	assert.Empty(t, msgs)
+	assert.Equal(t, 1, store.NextSenderMsgSeqNum())
+	assert.Equal(t, 0, store.bytes)
+
+	_, err = NewBoundedMemoryStoreFactory(-1, 0).Create(SessionID{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,7 @@
+//go:build mssql
+// +build mssql
+
+package main
+
+// the SQL Server driver is registered when built with -tags mssql, see the package comment of migrate-store.go
+import _ "github.com/denisenkom/go-mssqldb"
@@ -0,0 +1,7 @@
+//go:build mysql
+// +build mysql
+
+package main
+
+// the MySQL driver is registered when built with -tags mysql, see the package comment of migrate-store.go
+import _ "github.com/go-sql-driver/mysql"
@@ -0,0 +1,7 @@
+//go:build oracle
+// +build oracle
+
+package main
+
+// the Oracle driver is registered when built with -tags oracle, see the package comment of migrate-store.go
+import _ "github.com/godror/godror"
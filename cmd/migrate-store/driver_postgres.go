@@ -0,0 +1,7 @@
+//go:build postgres
+// +build postgres
+
+package main
+
+// the PostgreSQL driver is registered when built with -tags postgres, see the package comment of migrate-store.go
+import _ "github.com/lib/pq"
@@ -0,0 +1,126 @@
+// Command migrate-store moves sessions from one message store to another.
+//
+// Only the sqlite3 driver is built in. The drivers of the other SQL databases are registered by building with their
+// build tag, after adding the driver to the module with go get:
+//
+//	mysql     github.com/go-sql-driver/mysql
+//	postgres  github.com/lib/pq
+//	mssql     github.com/denisenkom/go-mssqldb
+//	oracle    github.com/godror/godror
+//
+// e.g. go get github.com/lib/pq && go install -tags postgres ./cmd/migrate-store
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/quickfixgo/quickfix"
+)
+
+var (
+	from    = flag.String("from", "", "type of the source store: file, sql, mongo, mmap or redis")
+	to      = flag.String("to", "", "type of the target store: file, sql, mongo, mmap or redis")
+	session = flag.String("session", "", "session to migrate, e.g. FIX.4.4:SENDER->TARGET, all sessions if not set")
+	dryRun  = flag.Bool("dry-run", false, "read and verify the source stores without writing the target stores")
+	force   = flag.Bool("force", false, "overwrite target stores that already hold messages or seqnums")
+
+	toSettings = flag.String("to-settings", "", "settings file of the target stores, with the same sessions, e.g. to migrate between two databases of the same type. The settings file if not set")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %v -from <type> -to <type> [flags] <path to settings file>\n", os.Args[0])
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func readSettings(fname string) (*quickfix.Settings, error) {
+	cfg, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("opening %v: %v", fname, err)
+	}
+	defer cfg.Close()
+
+	settings, err := quickfix.ParseSettings(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %v", fname, err)
+	}
+	return settings, nil
+}
+
+// storeFactory returns the MessageStoreFactory of a store type, configured by the settings of its type
+func storeFactory(storeType string, settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	switch storeType {
+	case "file":
+		return quickfix.NewFileStoreFactory(settings), nil
+	case "sql":
+		return quickfix.NewSQLStoreFactory(settings), nil
+	case "mongo":
+		return quickfix.NewMongoStoreFactory(settings), nil
+	case "mmap":
+		return quickfix.NewMmapStoreFactory(settings), nil
+	case "redis":
+		return quickfix.NewRedisStoreFactory(settings), nil
+	}
+
+	return nil, fmt.Errorf("unknown store type: %v", storeType)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 1 || *from == "" || *to == "" {
+		usage()
+	}
+
+	settings, err := readSettings(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	targetSettings := settings
+	if *toSettings != "" {
+		if targetSettings, err = readSettings(*toSettings); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	source, err := storeFactory(*from, settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	target, err := storeFactory(*to, targetSettings)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var sessionIDs []quickfix.SessionID
+	for sessionID := range settings.SessionSettings() {
+		if *session == "" || sessionID.String() == *session {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	if len(sessionIDs) == 0 {
+		log.Fatalf("No session %v in %v", *session, flag.Arg(0))
+	}
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	failed := false
+	for _, sessionID := range sessionIDs {
+		result, err := migrateSession(sessionID, source, target, options{dryRun: *dryRun, force: *force})
+		if err != nil {
+			log.Printf("%v: %v", sessionID, err)
+			failed = true
+			continue
+		}
+		log.Printf("%v: %v", sessionID, result)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// chunkSize is the number of seqnums read from the source store at once
+const chunkSize = 1000
+
+type options struct {
+	dryRun bool
+	force  bool
+}
+
+// summary is the state of a store that is compared between source and target
+type summary struct {
+	messages      int
+	checksum      string
+	nextSenderSeq int
+	nextTargetSeq int
+	creationTime  time.Time
+}
+
+func (s summary) String() string {
+	return fmt.Sprintf("%d messages, sha256 %s, next sender seqnum %d, next target seqnum %d, created %v",
+		s.messages, s.checksum, s.nextSenderSeq, s.nextTargetSeq, s.creationTime.UTC().Format(time.RFC3339))
+}
+
+type result struct {
+	summary
+	dryRun bool
+}
+
+func (r result) String() string {
+	if r.dryRun {
+		return "dry run, would migrate " + r.summary.String()
+	}
+	return "migrated " + r.summary.String()
+}
+
+// checksum hashes the seqnum and bytes of each message in seqnum order
+type checksum struct {
+	hash.Hash
+}
+
+func newChecksum() checksum {
+	return checksum{sha256.New()}
+}
+
+func (c checksum) add(seqNum int, msg []byte) {
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[0:8], uint64(seqNum))
+	binary.BigEndian.PutUint64(header[8:16], uint64(len(msg)))
+	c.Write(header[:])
+	c.Write(msg)
+}
+
+func (c checksum) String() string {
+	return hex.EncodeToString(c.Sum(nil))
+}
+
+// msgSeqNum returns the MsgSeqNum of a stored message
+func msgSeqNum(msg []byte) (int, error) {
+	m := quickfix.NewMessage()
+	if err := quickfix.ParseMessage(m, bytes.NewBuffer(append([]byte(nil), msg...))); err != nil {
+		return 0, err
+	}
+
+	seqNum, err := m.Header.GetInt(quickfix.Tag(34))
+	if err != nil {
+		return 0, err
+	}
+	return seqNum, nil
+}
+
+// forEachMessage calls f with the seqnum and bytes of each message of the store, in chunks of seqnums
+func forEachMessage(store quickfix.MessageStore, f func(seqNum int, msg []byte) error) error {
+	last := store.NextSenderMsgSeqNum() - 1
+	for begin := 1; begin <= last; begin += chunkSize {
+		end := begin + chunkSize - 1
+		if end > last {
+			end = last
+		}
+
+		msgs, err := store.GetMessages(begin, end)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range msgs {
+			seqNum, err := msgSeqNum(msg)
+			if err != nil {
+				return fmt.Errorf("invalid stored message: %v", err)
+			}
+			if err := f(seqNum, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// summarize reads every message of the store
+func summarize(store quickfix.MessageStore) (s summary, err error) {
+	sum := newChecksum()
+	err = forEachMessage(store, func(seqNum int, msg []byte) error {
+		sum.add(seqNum, msg)
+		s.messages++
+		return nil
+	})
+
+	s.checksum = sum.String()
+	s.nextSenderSeq = store.NextSenderMsgSeqNum()
+	s.nextTargetSeq = store.NextTargetMsgSeqNum()
+	s.creationTime = store.CreationTime()
+	return
+}
+
+// migrate copies the messages, seqnums and creation time of source to target, then verifies target against source
+func migrate(source, target quickfix.MessageStore, opts options) (result, error) {
+	sourceSummary, err := summarize(source)
+	if err != nil {
+		return result{}, fmt.Errorf("reading source: %v", err)
+	}
+	if opts.dryRun {
+		return result{summary: sourceSummary, dryRun: true}, nil
+	}
+
+	if !opts.force {
+		msgs, err := target.GetMessages(1, target.NextSenderMsgSeqNum())
+		if err != nil {
+			return result{}, fmt.Errorf("reading target: %v", err)
+		}
+		if len(msgs) > 0 || target.NextSenderMsgSeqNum() > 1 || target.NextTargetMsgSeqNum() > 1 {
+			return result{}, fmt.Errorf("target store is not empty, use -force to overwrite it")
+		}
+	}
+
+	if err := target.Reset(); err != nil {
+		return result{}, fmt.Errorf("resetting target: %v", err)
+	}
+
+	if err := forEachMessage(source, target.SaveMessage); err != nil {
+		return result{}, fmt.Errorf("copying messages: %v", err)
+	}
+	if err := target.SetNextSenderMsgSeqNum(source.NextSenderMsgSeqNum()); err != nil {
+		return result{}, fmt.Errorf("setting next sender seqnum: %v", err)
+	}
+	if err := target.SetNextTargetMsgSeqNum(source.NextTargetMsgSeqNum()); err != nil {
+		return result{}, fmt.Errorf("setting next target seqnum: %v", err)
+	}
+
+	setter, ok := target.(quickfix.CreationTimeSetter)
+	if !ok {
+		return result{}, fmt.Errorf("target store does not support setting the creation time")
+	}
+	if err := setter.SetCreationTime(source.CreationTime()); err != nil {
+		return result{}, fmt.Errorf("setting creation time: %v", err)
+	}
+
+	if err := target.Refresh(); err != nil {
+		return result{}, fmt.Errorf("refreshing target: %v", err)
+	}
+	targetSummary, err := summarize(target)
+	if err != nil {
+		return result{}, fmt.Errorf("verifying target: %v", err)
+	}
+
+	switch {
+	case targetSummary.messages != sourceSummary.messages:
+		return result{}, fmt.Errorf("verification failed: target has %d messages, source has %d", targetSummary.messages, sourceSummary.messages)
+	case targetSummary.checksum != sourceSummary.checksum:
+		return result{}, fmt.Errorf("verification failed: target checksum %s, source checksum %s", targetSummary.checksum, sourceSummary.checksum)
+	case targetSummary.nextSenderSeq != sourceSummary.nextSenderSeq || targetSummary.nextTargetSeq != sourceSummary.nextTargetSeq:
+		return result{}, fmt.Errorf("verification failed: target seqnums %d/%d, source seqnums %d/%d",
+			targetSummary.nextSenderSeq, targetSummary.nextTargetSeq, sourceSummary.nextSenderSeq, sourceSummary.nextTargetSeq)
+	// stores such as mongo keep the creation time to the millisecond
+	case !targetSummary.creationTime.Truncate(time.Millisecond).Equal(sourceSummary.creationTime.Truncate(time.Millisecond)):
+		return result{}, fmt.Errorf("verification failed: target created %v, source created %v", targetSummary.creationTime, sourceSummary.creationTime)
+	}
+
+	return result{summary: sourceSummary}, nil
+}
+
+// migrateSession creates the source and target stores of the session and migrates it. Target stores are not
+// created on a dry run.
+func migrateSession(sessionID quickfix.SessionID, sourceFactory, targetFactory quickfix.MessageStoreFactory, opts options) (result, error) {
+	source, err := sourceFactory.Create(sessionID)
+	if err != nil {
+		return result{}, fmt.Errorf("creating source store: %v", err)
+	}
+	defer source.Close()
+
+	if opts.dryRun {
+		return migrate(source, nil, opts)
+	}
+
+	target, err := targetFactory.Create(sessionID)
+	if err != nil {
+		return result{}, fmt.Errorf("creating target store: %v", err)
+	}
+	defer target.Close()
+
+	return migrate(source, target, opts)
+}
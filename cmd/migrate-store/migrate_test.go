@@ -0,0 +1,200 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+// newTestSettings returns settings of a file store and a sqlite store, the sqlite tables are created
+func newTestSettings(t *testing.T) *quickfix.Settings {
+	dir, err := ioutil.TempDir("", "migrate-store")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	dsn := path.Join(dir, "store.db")
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+
+	ddlFnames, err := filepath.Glob("../../_sql/sqlite3/*.sql")
+	require.Nil(t, err)
+	require.NotEmpty(t, ddlFnames)
+	for _, fname := range ddlFnames {
+		sqlBytes, err := ioutil.ReadFile(fname)
+		require.Nil(t, err)
+		_, err = db.Exec(string(sqlBytes))
+		require.Nil(t, err)
+	}
+
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+SQLStoreDriver=sqlite3
+SQLStoreDataSourceName=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, path.Join(dir, "store"), dsn, testSessionID.BeginString, testSessionID.SenderCompID, testSessionID.TargetCompID)))
+	require.Nil(t, err)
+
+	return settings
+}
+
+func testMessage(seqNum int) []byte {
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), "FIX.4.4")
+	msg.Header.SetString(quickfix.Tag(35), "0")
+	msg.Header.SetInt(quickfix.Tag(34), seqNum)
+	msg.Header.SetString(quickfix.Tag(49), "SENDER")
+	msg.Header.SetString(quickfix.Tag(56), "TARGET")
+	return []byte(msg.String())
+}
+
+// populate saves messages 1 to n in the file store of settings
+func populate(t *testing.T, settings *quickfix.Settings, n int) quickfix.MessageStore {
+	store, err := quickfix.NewFileStoreFactory(settings).Create(testSessionID)
+	require.Nil(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	for seqNum := 1; seqNum <= n; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, testMessage(seqNum)))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.SetNextTargetMsgSeqNum(42))
+	require.Nil(t, store.(quickfix.CreationTimeSetter).SetCreationTime(time.Date(2021, time.June, 1, 2, 3, 4, 5000000, time.UTC)))
+
+	return store
+}
+
+func TestMigrate(t *testing.T) {
+	settings := newTestSettings(t)
+	source := populate(t, settings, chunkSize+5)
+
+	r, err := migrateSession(testSessionID, quickfix.NewFileStoreFactory(settings), quickfix.NewSQLStoreFactory(settings), options{})
+	require.Nil(t, err)
+	assert.False(t, r.dryRun)
+	assert.Equal(t, chunkSize+5, r.messages)
+
+	target, err := quickfix.NewSQLStoreFactory(settings).Create(testSessionID)
+	require.Nil(t, err)
+	defer target.Close()
+
+	assert.Equal(t, source.NextSenderMsgSeqNum(), target.NextSenderMsgSeqNum())
+	assert.Equal(t, 42, target.NextTargetMsgSeqNum())
+	assert.True(t, source.CreationTime().Equal(target.CreationTime()))
+
+	msgs, err := target.GetMessages(3, 4)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{testMessage(3), testMessage(4)}, msgs)
+
+	// the target now holds the session
+	_, err = migrateSession(testSessionID, quickfix.NewFileStoreFactory(settings), quickfix.NewSQLStoreFactory(settings), options{})
+	assert.NotNil(t, err)
+
+	_, err = migrateSession(testSessionID, quickfix.NewFileStoreFactory(settings), quickfix.NewSQLStoreFactory(settings), options{force: true})
+	assert.Nil(t, err)
+}
+
+func TestMigrate_BetweenSQLSettings(t *testing.T) {
+	sourceSettings, targetSettings := newTestSettings(t), newTestSettings(t)
+
+	source, err := quickfix.NewSQLStoreFactory(sourceSettings).Create(testSessionID)
+	require.Nil(t, err)
+	defer source.Close()
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		require.Nil(t, source.SaveMessage(seqNum, testMessage(seqNum)))
+		require.Nil(t, source.IncrNextSenderMsgSeqNum())
+	}
+
+	r, err := migrateSession(testSessionID, quickfix.NewSQLStoreFactory(sourceSettings), quickfix.NewSQLStoreFactory(targetSettings), options{})
+	require.Nil(t, err)
+	assert.Equal(t, 3, r.messages)
+
+	target, err := quickfix.NewSQLStoreFactory(targetSettings).Create(testSessionID)
+	require.Nil(t, err)
+	defer target.Close()
+
+	assert.Equal(t, 4, target.NextSenderMsgSeqNum())
+	assert.True(t, source.CreationTime().Equal(target.CreationTime()))
+	msgs, err := target.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{testMessage(1), testMessage(2), testMessage(3)}, msgs)
+}
+
+func TestReadSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-store")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fname := path.Join(dir, "target.cfg")
+	require.Nil(t, ioutil.WriteFile(fname, []byte("[SESSION]\nBeginString=FIX.4.4\nSenderCompID=SENDER\nTargetCompID=TARGET\n"), 0600))
+	settings, err := readSettings(fname)
+	require.Nil(t, err)
+	assert.Contains(t, settings.SessionSettings(), testSessionID)
+
+	_, err = readSettings(path.Join(dir, "missing.cfg"))
+	assert.NotNil(t, err)
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	settings := newTestSettings(t)
+	populate(t, settings, 3)
+
+	r, err := migrateSession(testSessionID, quickfix.NewFileStoreFactory(settings), quickfix.NewSQLStoreFactory(settings), options{dryRun: true})
+	require.Nil(t, err)
+	assert.True(t, r.dryRun)
+	assert.Equal(t, 3, r.messages)
+	assert.Equal(t, 4, r.nextSenderSeq)
+
+	target, err := quickfix.NewSQLStoreFactory(settings).Create(testSessionID)
+	require.Nil(t, err)
+	defer target.Close()
+
+	msgs, err := target.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Empty(t, msgs)
+	assert.Equal(t, 1, target.NextSenderMsgSeqNum())
+}
+
+func TestMigrate_VerificationFailure(t *testing.T) {
+	settings := newTestSettings(t)
+	source := populate(t, settings, 2)
+
+	target, err := quickfix.NewSQLStoreFactory(settings).Create(testSessionID)
+	require.Nil(t, err)
+	defer target.Close()
+
+	_, err = migrate(source, droppingStore{target}, options{})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "verification failed")
+}
+
+// droppingStore loses every other message
+type droppingStore struct {
+	quickfix.MessageStore
+}
+
+func (s droppingStore) SaveMessage(seqNum int, msg []byte) error {
+	if seqNum%2 == 0 {
+		return nil
+	}
+	return s.MessageStore.SaveMessage(seqNum, msg)
+}
+
+func (s droppingStore) SetCreationTime(t time.Time) error {
+	return s.MessageStore.(quickfix.CreationTimeSetter).SetCreationTime(t)
+}
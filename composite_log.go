@@ -0,0 +1,259 @@
+package quickfix
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//compositeLogQueueSize is the number of entries queued for a child log, entries are dropped while its queue is full
+const compositeLogQueueSize = 1024
+
+//compositeChild is a child log of a compositeLog, logging the entries of its queue on its own goroutine
+type compositeChild struct {
+	log     Log
+	entries chan func(Log)
+	done    chan struct{}
+
+	//set once the log panicked, its entries are dropped from then on
+	disabled int32
+	//set once an entry was dropped because the queue was full
+	overflowed int32
+}
+
+type compositeLog struct {
+	children []*compositeChild
+
+	//guards the queues against entries after Close, and against reports once the queues are closed
+	mutex   sync.RWMutex
+	closed  bool
+	stopped bool
+	//entries queued and not yet logged, including the reports queued while logging them
+	pending sync.WaitGroup
+
+	//failures no other child was left to log, raised to the caller of the next entry
+	unreported chan string
+}
+
+func newCompositeLog(logs []Log) *compositeLog {
+	l := &compositeLog{unreported: make(chan string, 1)}
+	for _, log := range logs {
+		child := &compositeChild{log: log, entries: make(chan func(Log), compositeLogQueueSize), done: make(chan struct{})}
+		l.children = append(l.children, child)
+		go l.run(child)
+	}
+
+	return l
+}
+
+//forward calls f with log, returning the description of a panic of log
+func forward(log Log, f func(Log)) (panicked string) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = fmt.Sprintf("Log %T panicked: %v\n%s", log, r, debug.Stack())
+		}
+	}()
+
+	f(log)
+	return
+}
+
+//run logs the entries queued for child until Close. A child that panics is disabled, the panic is reported once.
+func (l *compositeLog) run(child *compositeChild) {
+	defer close(child.done)
+
+	for f := range child.entries {
+		if atomic.LoadInt32(&child.disabled) == 0 {
+			if panicked := forward(child.log, f); panicked != "" {
+				atomic.StoreInt32(&child.disabled, 1)
+				l.report(child, panicked, true)
+			}
+		}
+		l.pending.Done()
+	}
+}
+
+//report logs the failure of child to the other children. If none is left to log it and raise is set, it is raised to
+//the caller of the next entry.
+func (l *compositeLog) report(failed *compositeChild, message string, raise bool) {
+	event := LogEvent{Level: LogLevelError, Code: LogCodeLogFailed, Message: message}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	if l.stopped {
+		return
+	}
+
+	reported := false
+	for _, child := range l.children {
+		if child == failed || atomic.LoadInt32(&child.disabled) == 1 {
+			continue
+		}
+
+		//a full queue is reported as overflowed on its own
+		reported = true
+		l.pending.Add(1)
+		select {
+		case child.entries <- func(log Log) { logEvent(log, event) }:
+		default:
+			l.pending.Done()
+		}
+	}
+
+	if raise && !reported {
+		select {
+		case l.unreported <- message:
+		default:
+		}
+	}
+}
+
+//each queues f for every child log, so a child that blocks or fails does not hold up the others or the caller
+func (l *compositeLog) each(f func(Log)) {
+	l.mutex.RLock()
+	var overflowed []*compositeChild
+	if !l.closed {
+		for _, child := range l.children {
+			if atomic.LoadInt32(&child.disabled) == 1 {
+				continue
+			}
+
+			l.pending.Add(1)
+			select {
+			case child.entries <- f:
+			default:
+				l.pending.Done()
+				if atomic.CompareAndSwapInt32(&child.overflowed, 0, 1) {
+					overflowed = append(overflowed, child)
+				}
+			}
+		}
+	}
+	l.mutex.RUnlock()
+
+	for _, child := range overflowed {
+		l.report(child, fmt.Sprintf("Log %T is not keeping up, entries are dropped while its queue is full", child.log), false)
+	}
+
+	select {
+	case message := <-l.unreported:
+		panic(message)
+	default:
+	}
+}
+
+func (l *compositeLog) OnIncoming(msg []byte) {
+	msg = append([]byte(nil), msg...)
+	l.each(func(log Log) { log.OnIncoming(msg) })
+}
+
+func (l *compositeLog) OnOutgoing(msg []byte) {
+	msg = append([]byte(nil), msg...)
+	l.each(func(log Log) { log.OnOutgoing(msg) })
+}
+
+func (l *compositeLog) OnEvent(msg string) {
+	l.each(func(log Log) { log.OnEvent(msg) })
+}
+
+func (l *compositeLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+func (l *compositeLog) OnLogEvent(event LogEvent) {
+	l.each(func(log Log) { logEvent(log, event) })
+}
+
+//Close waits for the queued entries to be logged, then closes the child logs, returning the first error
+func (l *compositeLog) Close() (err error) {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mutex.Unlock()
+
+	l.pending.Wait()
+
+	l.mutex.Lock()
+	l.stopped = true
+	for _, child := range l.children {
+		close(child.entries)
+	}
+	l.mutex.Unlock()
+
+	for _, child := range l.children {
+		<-child.done
+		if closer, ok := child.log.(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	return
+}
+
+type compositeLogFactory struct {
+	factories []LogFactory
+}
+
+//NewCompositeLogFactory creates an instance of LogFactory that writes messages and events to the logs of each of
+//factories. Each log is written on its own goroutine from a bounded queue, so a log that blocks or fails does not hold
+//up the others. A log that panics is disabled, and a factory that fails to create its log, a disabled log and a log
+//whose queue overflows are logged to the logs of the other factories.
+func NewCompositeLogFactory(factories ...LogFactory) LogFactory {
+	return compositeLogFactory{factories: factories}
+}
+
+func (f compositeLogFactory) Create() (Log, error) {
+	return f.create(func(factory LogFactory) (Log, error) { return factory.Create() })
+}
+
+func (f compositeLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return f.create(func(factory LogFactory) (Log, error) { return factory.CreateSessionLog(sessionID) })
+}
+
+//logFactoryErrors are the errors of the factories of a composite log
+type logFactoryErrors []error
+
+func (e logFactoryErrors) Error() string {
+	errs := make([]string, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err.Error())
+	}
+
+	return strings.Join(errs, "; ")
+}
+
+//create builds one child log per factory. The factories that fail are logged to the children that were built, it is
+//an error only if every factory fails.
+func (f compositeLogFactory) create(createLog func(LogFactory) (Log, error)) (Log, error) {
+	var logs []Log
+	var errs logFactoryErrors
+	for _, factory := range f.factories {
+		log, err := createLog(factory)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		logs = append(logs, log)
+	}
+
+	if len(logs) == 0 && len(errs) > 0 {
+		return nil, errs
+	}
+
+	l := newCompositeLog(logs)
+	if len(errs) > 0 {
+		l.OnLogEvent(LogEvent{Level: LogLevelError, Code: LogCodeLogFailed,
+			Message: fmt.Sprintf("Failed to create %d of %d logs: %v", len(errs), len(f.factories), errs), Attrs: []LogAttr{{"error", errs}}})
+	}
+	return l, nil
+}
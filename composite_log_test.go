@@ -0,0 +1,176 @@
+package quickfix
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type captureLog struct {
+	entries *[]string
+	closed  *bool
+}
+
+func (l captureLog) OnIncoming(msg []byte)                    { *l.entries = append(*l.entries, "in:"+string(msg)) }
+func (l captureLog) OnOutgoing(msg []byte)                    { *l.entries = append(*l.entries, "out:"+string(msg)) }
+func (l captureLog) OnEvent(msg string)                       { *l.entries = append(*l.entries, "event:"+msg) }
+func (l captureLog) OnEventf(format string, v ...interface{}) {}
+func (l captureLog) Close() error {
+	*l.closed = true
+	return nil
+}
+
+type captureLogFactory struct {
+	captureLog
+	err error
+}
+
+func newCaptureLogFactory() captureLogFactory {
+	return captureLogFactory{captureLog: captureLog{entries: new([]string), closed: new(bool)}}
+}
+
+func (f captureLogFactory) Create() (Log, error) {
+	return f.captureLog, f.err
+}
+
+func (f captureLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return f.captureLog, f.err
+}
+
+type panicLog struct{ nullLog }
+
+func (panicLog) OnEvent(string) { panic("failed") }
+
+type panicLogFactory struct{}
+
+func (panicLogFactory) Create() (Log, error)                              { return panicLog{}, nil }
+func (panicLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) { return panicLog{}, nil }
+
+//blockingLog blocks logging messages until unblock is closed
+type blockingLog struct {
+	nullLog
+	unblock chan struct{}
+}
+
+func (l blockingLog) OnIncoming([]byte) { <-l.unblock }
+
+type blockingLogFactory struct{ blockingLog }
+
+func (f blockingLogFactory) Create() (Log, error) {
+	return f.blockingLog, nil
+}
+
+func (f blockingLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return f.blockingLog, nil
+}
+
+func TestCompositeLog(t *testing.T) {
+	first, second := newCaptureLogFactory(), newCaptureLogFactory()
+	factory := NewCompositeLogFactory(first, panicLogFactory{}, second)
+
+	log, err := factory.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "S", TargetCompID: "T"})
+	require.Nil(t, err)
+
+	log.OnIncoming([]byte("a"))
+	log.OnOutgoing([]byte("b"))
+	log.OnEventf("c%d", 1)
+	log.OnEvent("d")
+	closeLog(log)
+
+	//the panic is logged to the others once, in between the entries they were queued
+	for _, entries := range []*[]string{first.entries, second.entries} {
+		var logged, panics []string
+		for _, entry := range *entries {
+			if strings.HasPrefix(entry, "event:Log quickfix.panicLog panicked: failed\n") {
+				panics = append(panics, entry)
+			} else {
+				logged = append(logged, entry)
+			}
+		}
+		assert.Equal(t, []string{"in:a", "out:b", "event:c1", "event:d"}, logged, "a failing log should not block the others")
+		assert.Len(t, panics, 1, "the panic should be logged to the others once")
+	}
+
+	assert.True(t, *first.closed)
+	assert.True(t, *second.closed)
+}
+
+//pacedLog passes the messages it logs to the test, so the test can keep pace with it
+type pacedLog struct {
+	nullLog
+	incoming chan []byte
+	events   chan string
+}
+
+func (l pacedLog) OnIncoming(msg []byte) { l.incoming <- msg }
+func (l pacedLog) OnEvent(msg string)    { l.events <- msg }
+
+type pacedLogFactory struct{ pacedLog }
+
+func (f pacedLogFactory) Create() (Log, error)                              { return f.pacedLog, nil }
+func (f pacedLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) { return f.pacedLog, nil }
+
+func TestCompositeLog_Blocking(t *testing.T) {
+	paced := pacedLogFactory{pacedLog{incoming: make(chan []byte), events: make(chan string, 10)}}
+	blocking := blockingLogFactory{blockingLog{unblock: make(chan struct{})}}
+	log, err := NewCompositeLogFactory(blocking, paced).Create()
+	require.Nil(t, err)
+
+	for i := 0; i < 2*compositeLogQueueSize; i++ {
+		log.OnIncoming([]byte("a"))
+		select {
+		case msg := <-paced.incoming:
+			require.Equal(t, "a", string(msg))
+		case <-time.After(5 * time.Second):
+			t.Fatal("a blocking log should not block the others")
+		}
+	}
+
+	close(blocking.unblock)
+	closeLog(log)
+	close(paced.events)
+
+	var events []string
+	for event := range paced.events {
+		events = append(events, event)
+	}
+	require.Len(t, events, 1, "the overflow should be logged once")
+	assert.True(t, strings.HasPrefix(events[0], "Log quickfix.blockingLog is not keeping up"))
+}
+
+func TestCompositeLog_Panic(t *testing.T) {
+	log, err := NewCompositeLogFactory(panicLogFactory{}).Create()
+	require.Nil(t, err)
+
+	log.OnEvent("a")
+	child := log.(*compositeLog).children[0]
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&child.disabled) == 1 }, 5*time.Second, time.Millisecond)
+
+	assert.Panics(t, func() { log.OnIncoming([]byte("b")) }, "a panic no log is left to log should not be swallowed")
+	assert.NotPanics(t, func() { log.OnEvent("c") }, "the panic should be raised once")
+	closeLog(log)
+}
+
+func TestCompositeLog_CreateError(t *testing.T) {
+	first, failing := newCaptureLogFactory(), newCaptureLogFactory()
+	failing.err = errors.New("create failed")
+
+	log, err := NewCompositeLogFactory(first, failing).Create()
+	require.Nil(t, err)
+	assert.False(t, *first.closed)
+
+	log.OnEvent("a")
+	closeLog(log)
+	assert.Equal(t, []string{"event:Failed to create 1 of 2 logs: create failed", "event:a"}, *first.entries, "the failure should be logged to the created logs")
+
+	otherFailing := newCaptureLogFactory()
+	otherFailing.err = errors.New("other create failed")
+	_, err = NewCompositeLogFactory(failing, otherFailing).Create()
+	require.NotNil(t, err)
+	assert.Equal(t, "create failed; other create failed", err.Error())
+}
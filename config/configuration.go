@@ -46,13 +46,46 @@ const (
 	ReconnectInterval            string = "ReconnectInterval"
 	LogoutTimeout                string = "LogoutTimeout"
 	LogonTimeout                 string = "LogonTimeout"
+	ReconnectIntervalMax         string = "ReconnectIntervalMax"
+	ReconnectJitter              string = "ReconnectJitter"
+	MaxReconnectAttempts         string = "MaxReconnectAttempts"
+	SocketConnectFailback        string = "SocketConnectFailback"
 	HeartBtInt                   string = "HeartBtInt"
 	HeartBtIntOverride           string = "HeartBtIntOverride"
 	FileLogPath                  string = "FileLogPath"
+	FileLogMaxSize               string = "FileLogMaxSize"
+	FileLogRotateAtEndTime       string = "FileLogRotateAtEndTime"
+	FileLogCompress              string = "FileLogCompress"
+	FileLogBackupCount           string = "FileLogBackupCount"
+	LogMaskTags                  string = "LogMaskTags"
+	LogSuppressMsgTypes          string = "LogSuppressMsgTypes"
 	FileStorePath                string = "FileStorePath"
+	FileStoreArchive             string = "FileStoreArchive"
+	FileStoreRetainSeqNums       string = "FileStoreRetainSeqNums"
+	FileStoreSync                string = "FileStoreSync"
+	FileStoreSyncInterval        string = "FileStoreSyncInterval"
+	FileStoreSyncBatchSize       string = "FileStoreSyncBatchSize"
+	MmapStorePath                string = "MmapStorePath"
+	MmapStoreSegmentSize         string = "MmapStoreSegmentSize"
 	SQLStoreDriver               string = "SQLStoreDriver"
 	SQLStoreDataSourceName       string = "SQLStoreDataSourceName"
 	SQLStoreConnMaxLifetime      string = "SQLStoreConnMaxLifetime"
+	SQLStoreTablePrefix          string = "SQLStoreTablePrefix"
+	SQLStoreCreateSchema         string = "SQLStoreCreateSchema"
+	SQLLogDriver                 string = "SQLLogDriver"
+	SQLLogDataSourceName         string = "SQLLogDataSourceName"
+	SQLLogConnMaxLifetime        string = "SQLLogConnMaxLifetime"
+	SQLLogTablePrefix            string = "SQLLogTablePrefix"
+	SQLLogCreateSchema           string = "SQLLogCreateSchema"
+	RedisStoreAddress            string = "RedisStoreAddress"
+	RedisStorePassword           string = "RedisStorePassword"
+	RedisStoreDB                 string = "RedisStoreDB"
+	RedisStoreKeyPrefix          string = "RedisStoreKeyPrefix"
+	RedisLogAddress              string = "RedisLogAddress"
+	RedisLogPassword             string = "RedisLogPassword"
+	RedisLogDB                   string = "RedisLogDB"
+	RedisLogKeyPrefix            string = "RedisLogKeyPrefix"
+	RedisLogMaxLen               string = "RedisLogMaxLen"
 	MongoStoreConnection         string = "MongoStoreConnection"
 	MongoStoreDatabase           string = "MongoStoreDatabase"
 	ValidateFieldsOutOfOrder     string = "ValidateFieldsOutOfOrder"
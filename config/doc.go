@@ -230,6 +230,30 @@ Time between reconnection attempts in seconds. Only used for initiators.    Valu
 
 Defaults to 30
 
+ReconnectIntervalMax
+
+Maximum time between reconnection attempts in seconds. If set, the time between reconnection attempts doubles with each consecutive failed attempt, starting from ReconnectInterval, up to ReconnectIntervalMax. Only used for initiators. Value must be positive integer not less than ReconnectInterval.
+
+ReconnectJitter
+
+Percentage by which the time between reconnection attempts is randomly varied up or down, to avoid many initiators reconnecting at once. Only used for initiators. Value must be an integer between 0 and 100.
+
+Defaults to 0
+
+MaxReconnectAttempts
+
+Number of consecutive failed connection attempts after which the initiator gives up reconnecting the session. The Application is notified if it implements ReconnectFailureHandler. Only used for initiators. Value must be non-negative integer, 0 means no limit.
+
+Defaults to 0
+
+SocketConnectFailback
+
+If set to Y, SocketConnectHost and SocketConnectPort are the primary address and SocketConnectHost<n> and SocketConnectPort<n> are backups. Connection attempts always start with the primary and, while connected to a backup, the primary is probed every ReconnectInterval. Once the primary is reachable, the session logs out and reconnects to it. If set to N, addresses are tried in turn. Only used for initiators. Valid Values:
+ Y
+ N
+
+Defaults to N.
+
 LogoutTimeout
 
 Session setting for logout timeout in seconds. Only used for initiators. Value must be positive integer.
@@ -351,17 +375,150 @@ FileLogPath
 
 Directory to store logs.	Value must be valid directory for storing files, application must have write access.
 
+FileLogMaxSize
+
+Rotate the message and event log files of the session once they would exceed this size in bytes.  Rotated files are named with the UTC time of rotation, e.g. FIX.4.2-SENDER-TARGET.messages.20230102-150405.000000.log.  Only used with FileLogFactory.  Valid Values:
+ Integer greater than or equal to 0
+
+Defaults to 0, which disables rotation by size.
+
+FileLogRotateAtEndTime
+
+Rotate the log files of the session on the first write after each EndTime.  Requires StartTime and EndTime.  Only used with FileLogFactory.  Valid Values:
+ Y
+ N
+
+Defaults to N.
+
+FileLogCompress
+
+Compress rotated log files with gzip, adding a .gz extension.  Only used with FileLogFactory.  Valid Values:
+ Y
+ N
+
+Defaults to N.
+
+FileLogBackupCount
+
+The number of rotated files to keep for each log file of the session, the oldest are removed.  Only used with FileLogFactory.  Valid Values:
+ Integer greater than or equal to 0
+
+Defaults to 0, which keeps all rotated files.
+
+LogMaskTags
+
+Comma separated list of tags whose values are replaced with *** in logged messages, e.g. Password and NewPassword.  Only used with FilteredLogFactory.
+
+Example Values:
+ LogMaskTags=554,925
+ LogMaskTags=554,925,1 # also mask Account
+
+LogSuppressMsgTypes
+
+Comma separated list of MsgTypes that are not logged.  Only used with FilteredLogFactory.
+
+Example Values:
+ LogSuppressMsgTypes=0,1 # suppress Heartbeat and TestRequest
+
 FileStorePath
 
 Directory to store sequence number and message files.  Only used with FileStoreFactory.
 
+FileStoreArchive
+
+On reset, move the store files of the session to a directory archive/<creation time> under FileStorePath, e.g. archive/20230102-080000, rather than deleting them.  Only used with FileStoreFactory.  Valid Values:
+ Y
+ N
+
+Defaults to N.
+
+FileStoreRetainSeqNums
+
+The number of sequence numbers of sent messages kept in the store for resend.  Older messages are dropped by compacting the store files once twice as many messages are stored.  Compaction runs inline in the save of the message that triggers it, which copies the retained messages, so keep the value small for latency sensitive sessions.  Only used with FileStoreFactory.  Valid Values:
+ Integer greater than or equal to 0
+
+Defaults to 0, which keeps all messages until reset.
+
+FileStoreSync
+
+When the store files are flushed to disk with fsync.  Only used with FileStoreFactory and MmapStoreFactory.  Valid Values:
+ ALWAYS - fsync each write before it returns
+ BATCH - group commit, fsync once FileStoreSyncBatchSize writes are pending or every FileStoreSyncInterval
+ NEVER - leave flushing to the operating system
+
+Defaults to ALWAYS.  On restart, records torn by a crash are dropped from the store.
+
+FileStoreSyncInterval
+
+The interval of the BATCH group commit.  Only used with FileStoreFactory and MmapStoreFactory.  Valid Values:
+ Duration, e.g. 500ms, 0 disables the interval
+
+Defaults to 1s if FileStoreSyncBatchSize is not set.
+
+FileStoreSyncBatchSize
+
+The number of pending writes that trigger the BATCH group commit.  Only used with FileStoreFactory and MmapStoreFactory.  Valid Values:
+ Integer greater than or equal to 0, 0 disables the batch size
+
+Defaults to 0.
+
+MmapStorePath
+
+Directory to store segmented message logs and sequence numbers.  Only used with MmapStoreFactory, do not share the directory with FileStoreFactory.
+
+MmapStoreSegmentSize
+
+The size in bytes at which a segment is sealed and a new one is started.  Sealed segments are memory mapped for resends, only the active segment is read when the store is opened.  Only used with MmapStoreFactory.  Valid Values:
+ Integer greater than 0 and less than 4294967296
+
+Defaults to 67108864 (64MiB).
+
+RedisStoreAddress
+
+The host:port of the redis server to use.  Only used with RedisStoreFactory.
+
+RedisStorePassword
+
+The password to AUTH with.  Only used with RedisStoreFactory.  Defaults to none.
+
+RedisStoreDB
+
+The database to SELECT.  Only used with RedisStoreFactory.  Defaults to 0.
+
+RedisStoreKeyPrefix
+
+The prefix of the keys of each session, <prefix><session>:session is the hash of the seqnums and creation time and <prefix><session>:messages is the sorted set of messages.  Only used with RedisStoreFactory.  Defaults to quickfix:
+
+RedisLogAddress
+
+The host:port of the redis server to use.  Only used with RedisLogFactory.
+
+RedisLogPassword
+
+The password to AUTH with.  Only used with RedisLogFactory.  Defaults to none.
+
+RedisLogDB
+
+The database to SELECT.  Only used with RedisLogFactory.  Defaults to 0.
+
+RedisLogKeyPrefix
+
+The prefix of the stream keys, <prefix><session>:log for each session and <prefix>global:log for the global log.  Only used with RedisLogFactory.  Defaults to quickfix:
+
+RedisLogMaxLen
+
+The approximate number of entries kept in each stream.  Only used with RedisLogFactory.  Valid Values:
+ Integer greater than or equal to 0
+
+Defaults to 0, which keeps all entries.
+
 MongoStoreConnection
 
-The MongoDB connection URL to use (see https://godoc.org/github.com/globalsign/mgo#Dial for the URL Format).  Only used with MongoStoreFactory.
+The MongoDB connection URL to use (see https://godoc.org/github.com/globalsign/mgo#Dial for the URL Format).  Only used with MongoStoreFactory and MongoLogFactory.
 
 MongoStoreDatabase
 
-The MongoDB-specific name of the database to use.  Only used with MongoStoreFactory.
+The MongoDB-specific name of the database to use.  Only used with MongoStoreFactory and MongoLogFactory.
 
 SQLStoreDriver
 
@@ -380,5 +537,41 @@ If your database server has a config option to close inactive connections after
 Example Values:
  SQLConnMaxLifetime=14400s # 14400 seconds
  SQLConnMaxLifetime=2h45m  # 2 hours and 45 minutes
+
+SQLStoreTablePrefix
+
+Prefix of the names of the sessions and messages tables, e.g. qf_ for the tables qf_sessions and qf_messages.  The prefix may qualify the tables by a schema, e.g. quickfix. or quickfix.qf_, so that several engines can share one database.  Only used with SqlStoreFactory.
+
+SQLStoreCreateSchema
+
+If set to Y, the sessions and messages tables are created if missing when the store is created, and migrated to the latest schema version.  The schema version is recorded in the schema_version table, named with SQLStoreTablePrefix.  Tables created by the scripts under _sql are kept.  Supported with the sqlite3, mysql, postgres, pgx, sqlserver, mssql, godror, goracle and oci8 drivers.  Only used with SqlStoreFactory.  Valid Values:
+ Y
+ N
+
+Defaults to N.
+
+SQLLogDriver
+
+The name of the database driver to use for logging.  Messages are written to the messages_log table and events to the event_log table.  Only used with SQLLogFactory.
+
+SQLLogDataSourceName
+
+The driver-specific data source name of the database to use for logging.  Only used with SQLLogFactory.
+
+SQLLogConnMaxLifetime
+
+The maximum duration of time that a logging database connection may be reused, see SQLStoreConnMaxLifetime.  Defaults to zero, which causes connections to be reused forever.  Only used with SQLLogFactory.
+
+SQLLogTablePrefix
+
+Prefix of the names of the messages_log and event_log tables, see SQLStoreTablePrefix.  Only used with SQLLogFactory.
+
+SQLLogCreateSchema
+
+If set to Y, the messages_log and event_log tables are created if missing when the log is created, and migrated to the latest schema version, see SQLStoreCreateSchema.  Only used with SQLLogFactory.  Valid Values:
+ Y
+ N
+
+Defaults to N.
 */
 package config
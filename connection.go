@@ -2,16 +2,21 @@ package quickfix
 
 import "io"
 
-func writeLoop(connection io.Writer, messageOut chan []byte, log Log) {
+func writeLoop(connection io.Writer, messageOut chan fixOut, log Log) {
 	for {
 		msg, ok := <-messageOut
 		if !ok {
 			return
 		}
 
-		if _, err := connection.Write(msg); err != nil {
+		_, err := connection.Write(msg.bytes)
+		if err != nil {
 			log.OnEvent(err.Error())
 		}
+
+		if msg.sent != nil {
+			msg.sent <- err
+		}
 	}
 }
 
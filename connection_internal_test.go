@@ -2,18 +2,20 @@ package quickfix
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 )
 
 func TestWriteLoop(t *testing.T) {
 	writer := bytes.NewBufferString("")
-	msgOut := make(chan []byte)
+	msgOut := make(chan fixOut)
 
 	go func() {
-		msgOut <- []byte("test msg 1 ")
-		msgOut <- []byte("test msg 2 ")
-		msgOut <- []byte("test msg 3")
+		msgOut <- fixOut{bytes: []byte("test msg 1 ")}
+		msgOut <- fixOut{bytes: []byte("test msg 2 ")}
+		msgOut <- fixOut{bytes: []byte("test msg 3")}
 		close(msgOut)
 	}()
 	writeLoop(writer, msgOut, nullLog{})
@@ -25,6 +27,36 @@ func TestWriteLoop(t *testing.T) {
 	}
 }
 
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriteLoop_Sent(t *testing.T) {
+	for _, writeErr := range []error{nil, errors.New("connection reset")} {
+		var writer io.Writer = bytes.NewBufferString("")
+		if writeErr != nil {
+			writer = failingWriter{writeErr}
+		}
+
+		msgOut := make(chan fixOut)
+		sent := make(chan error, 1)
+		go func() {
+			msgOut <- fixOut{bytes: []byte("test msg"), sent: sent}
+			close(msgOut)
+		}()
+		writeLoop(writer, msgOut, nullLog{})
+
+		select {
+		case err := <-sent:
+			if err != writeErr {
+				t.Errorf("expected %v got %v", writeErr, err)
+			}
+		default:
+			t.Error("the outcome of the write should be sent")
+		}
+	}
+}
+
 func TestReadLoop(t *testing.T) {
 	msgIn := make(chan fixIn)
 	stream := "hello8=FIX.4.09=5blah10=103garbage8=FIX.4.09=4foo10=103"
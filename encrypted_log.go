@@ -0,0 +1,95 @@
+package quickfix
+
+import (
+	"fmt"
+	"io"
+)
+
+type encryptedLog struct {
+	Log
+	cipher *recordCipher
+}
+
+//encrypt returns the record of text, or a note of the error as there is no way to report it
+func (l encryptedLog) encrypt(kind recordKind, text []byte) []byte {
+	record, err := l.cipher.seal(kind, 0, text, nil)
+	if err != nil {
+		return []byte(fmt.Sprintf("encryption failed: %v", err))
+	}
+	return record
+}
+
+func (l encryptedLog) OnIncoming(msg []byte) {
+	l.Log.OnIncoming(l.encrypt(recordKindIncoming, msg))
+}
+
+func (l encryptedLog) OnOutgoing(msg []byte) {
+	l.Log.OnOutgoing(l.encrypt(recordKindOutgoing, msg))
+}
+
+func (l encryptedLog) OnEvent(msg string) {
+	l.Log.OnEvent(string(l.encrypt(recordKindEvent, []byte(msg))))
+}
+
+func (l encryptedLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+func (l encryptedLog) Close() error {
+	if closer, ok := l.Log.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type encryptedLogFactory struct {
+	factory LogFactory
+	cipher  *recordCipher
+}
+
+//NewEncryptedLogFactory wraps factory to encrypt messages and events with AES-GCM, with the keys of keys, before they
+//are logged, e.g. to encrypt the files of NewFileLogFactory. Each message or event is logged as a base64 encoded
+//record on its own, see DecryptLogRecord to read it back.
+func NewEncryptedLogFactory(factory LogFactory, keys KeyProvider) LogFactory {
+	return encryptedLogFactory{factory: factory, cipher: newRecordCipher(keys)}
+}
+
+//logRecordCodes are the codes of the kinds of log records
+var logRecordCodes = map[recordKind]string{
+	recordKindIncoming: LogCodeIncoming,
+	recordKindOutgoing: LogCodeOutgoing,
+	recordKindEvent:    LogCodeEvent,
+}
+
+//DecryptLogRecord decrypts a record logged by a log of NewEncryptedLogFactory, e.g. the text of a line of a file log
+//after its timestamp. code is LogCodeIncoming, LogCodeOutgoing or LogCodeEvent for what the record was logged as.
+func DecryptLogRecord(keys KeyProvider, record []byte) (code string, text []byte, err error) {
+	text, kind, _, err := newRecordCipher(keys).open(record, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	code, ok := logRecordCodes[kind]
+	if !ok {
+		return "", nil, fmt.Errorf("unexpected encrypted record kind: %d", kind)
+	}
+	return code, text, nil
+}
+
+func (f encryptedLogFactory) Create() (Log, error) {
+	log, err := f.factory.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptedLog{Log: log, cipher: f.cipher}, nil
+}
+
+func (f encryptedLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	log, err := f.factory.CreateSessionLog(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptedLog{Log: log, cipher: f.cipher}, nil
+}
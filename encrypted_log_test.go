@@ -0,0 +1,73 @@
+package quickfix
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedLog_FileLog(t *testing.T) {
+	logPath, err := ioutil.TempDir("", "EncryptedLogTest")
+	require.Nil(t, err)
+	defer os.RemoveAll(logPath)
+
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileLogPath=%s
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET`, logPath)))
+	require.Nil(t, err)
+	fileLogFactory, err := NewFileLogFactory(settings)
+	require.Nil(t, err)
+
+	keys := newTestKeyProvider(t, "key1", "key1")
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	log, err := NewEncryptedLogFactory(fileLogFactory, keys).CreateSessionLog(sessionID)
+	require.Nil(t, err)
+
+	log.OnIncoming([]byte("8=FIX.4.4\x0135=D\x011=ACCOUNT\x01"))
+	log.OnOutgoing([]byte("8=FIX.4.4\x0135=8\x011=ACCOUNT\x01"))
+	log.OnEventf("order for %v", "ACCOUNT")
+	require.Nil(t, log.(io.Closer).Close())
+
+	// records are decrypted from the lines after their timestamps, with what they were logged as
+	readLog := func(name string) (texts []string) {
+		contents, err := ioutil.ReadFile(path.Join(logPath, sessionIDFilenamePrefix(sessionID)+name))
+		require.Nil(t, err)
+		assert.NotContains(t, string(contents), "ACCOUNT")
+
+		for _, line := range bytes.Split(bytes.TrimSuffix(contents, []byte("\n")), []byte("\n")) {
+			code, text, err := DecryptLogRecord(keys, line[bytes.LastIndexByte(line, ' ')+1:])
+			require.Nil(t, err)
+			texts = append(texts, code+":"+string(text))
+		}
+		return
+	}
+
+	assert.Equal(t, []string{"incoming:8=FIX.4.4\x0135=D\x011=ACCOUNT\x01", "outgoing:8=FIX.4.4\x0135=8\x011=ACCOUNT\x01"}, readLog(".messages.current.log"))
+	assert.Equal(t, []string{"event:order for ACCOUNT"}, readLog(".event.current.log"))
+
+	_, _, err = DecryptLogRecord(newTestKeyProvider(t, "key2", "key2"), []byte("AQRrZXkx"))
+	assert.NotNil(t, err)
+
+	// the kind of a record is authenticated
+	record, err := newRecordCipher(keys).seal(recordKindIncoming, 0, []byte("8=FIX.4.4\x01"), nil)
+	require.Nil(t, err)
+	raw, err := base64.StdEncoding.DecodeString(string(record))
+	require.Nil(t, err)
+	raw[1] = byte(recordKindOutgoing)
+	_, _, err = DecryptLogRecord(keys, []byte(base64.StdEncoding.EncodeToString(raw)))
+	assert.NotNil(t, err)
+}
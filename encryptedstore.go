@@ -0,0 +1,341 @@
+package quickfix
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//KeyProvider supplies the AES keys of encrypted stores and logs. Keys are 16, 24 or 32 bytes long, for AES-128,
+//AES-192 or AES-256. Each record is encrypted with the current key and carries its key ID, so keys are rotated by
+//changing the current key while the provider still returns the previous keys for the records encrypted with them.
+//
+//The key of a key ID must not change, as ciphers are cached by key ID. CurrentKey is called once a minute, so new
+//records are encrypted with a rotated key within a minute.
+type KeyProvider interface {
+	//CurrentKey returns the ID and the key to encrypt new records with
+	CurrentKey() (keyID string, key []byte, err error)
+
+	//Key returns the key of keyID, to decrypt the records encrypted with it
+	Key(keyID string) ([]byte, error)
+}
+
+type staticKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+//NewStaticKeyProvider returns a KeyProvider of a fixed set of keys by key ID, encrypting with the key of currentKeyID.
+//Key IDs are 1 to 255 bytes long.
+func NewStaticKeyProvider(currentKeyID string, keys map[string][]byte) (KeyProvider, error) {
+	p := staticKeyProvider{currentKeyID: currentKeyID, keys: make(map[string][]byte, len(keys))}
+	for keyID, key := range keys {
+		if len(keyID) == 0 || len(keyID) > 255 {
+			return nil, fmt.Errorf("invalid key ID: %q", keyID)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, errors.Wrapf(err, "key %v", keyID)
+		}
+		p.keys[keyID] = append([]byte(nil), key...)
+	}
+
+	if _, ok := p.keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("unknown current key ID: %q", currentKeyID)
+	}
+	return p, nil
+}
+
+func (p staticKeyProvider) CurrentKey() (string, []byte, error) {
+	return p.currentKeyID, p.keys[p.currentKeyID], nil
+}
+
+func (p staticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID: %q", keyID)
+	}
+	return key, nil
+}
+
+//encryptedRecordVersion is the format of encrypted records, the first byte of a decoded record
+const encryptedRecordVersion = 1
+
+//recordKind is what a record holds, it is authenticated with the record so that records cannot be swapped between
+//kinds, e.g. an incoming message logged as an outgoing one
+type recordKind byte
+
+const (
+	recordKindStoredMessage recordKind = iota + 1
+	recordKindIncoming
+	recordKindOutgoing
+	recordKindEvent
+)
+
+//currentKeyTTL is how long the current key is used before the KeyProvider is asked for it again
+const currentKeyTTL = time.Minute
+
+//recordCipher encrypts records with AES-GCM. A record is base64 encoded, so that it can be stored in text columns
+//and logged on a line, from the bytes
+//
+//	version | kind | seqnum | key ID length | key ID | nonce | ciphertext and tag
+//
+//The seqnum is 8 bytes, 0 for log records. The bytes before the nonce are authenticated with the additional data of
+//the record.
+type recordCipher struct {
+	keys KeyProvider
+
+	mutex        sync.Mutex
+	gcms         map[string]cipher.AEAD
+	currentKeyID string
+	currentUntil time.Time
+}
+
+func newRecordCipher(keys KeyProvider) *recordCipher {
+	return &recordCipher{keys: keys, gcms: make(map[string]cipher.AEAD)}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//gcm returns the cached cipher of keyID, creating it from key, or from the KeyProvider if key is nil
+func (c *recordCipher) gcm(keyID string, key []byte) (cipher.AEAD, error) {
+	if gcm, ok := c.gcms[keyID]; ok {
+		return gcm, nil
+	}
+
+	if key == nil {
+		var err error
+		if key, err = c.keys.Key(keyID); err != nil {
+			return nil, err
+		}
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "key %v", keyID)
+	}
+	c.gcms[keyID] = gcm
+	return gcm, nil
+}
+
+//currentGCM returns the ID and the cipher of the current key
+func (c *recordCipher) currentGCM() (string, cipher.AEAD, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if now := time.Now(); c.currentKeyID == "" || !now.Before(c.currentUntil) {
+		keyID, key, err := c.keys.CurrentKey()
+		if err != nil {
+			return "", nil, err
+		}
+		if len(keyID) == 0 || len(keyID) > 255 {
+			return "", nil, fmt.Errorf("invalid key ID: %q", keyID)
+		}
+		if _, err := c.gcm(keyID, key); err != nil {
+			return "", nil, err
+		}
+		c.currentKeyID, c.currentUntil = keyID, now.Add(currentKeyTTL)
+	}
+
+	return c.currentKeyID, c.gcms[c.currentKeyID], nil
+}
+
+//keyGCM returns the cipher of keyID
+func (c *recordCipher) keyGCM(keyID string) (cipher.AEAD, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.gcm(keyID, nil)
+}
+
+//recordHeaderSize is the size of the header of a record before its key ID
+const recordHeaderSize = 11
+
+//seal encrypts plaintext of kind and seqNum with the current key, authenticating additionalData with it
+func (c *recordCipher) seal(kind recordKind, seqNum int, plaintext, additionalData []byte) ([]byte, error) {
+	keyID, gcm, err := c.currentGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, recordHeaderSize, recordHeaderSize+len(keyID)+gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	raw[0], raw[1], raw[10] = encryptedRecordVersion, byte(kind), byte(len(keyID))
+	binary.BigEndian.PutUint64(raw[2:10], uint64(seqNum))
+	raw = append(raw, keyID...)
+	header := raw
+
+	nonce := raw[len(raw) : len(raw)+gcm.NonceSize()]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	raw = gcm.Seal(raw[:len(raw)+len(nonce)], nonce, plaintext, append(append([]byte(nil), header...), additionalData...))
+
+	record := make([]byte, base64.StdEncoding.EncodedLen(len(raw)))
+	base64.StdEncoding.Encode(record, raw)
+	return record, nil
+}
+
+//open decrypts a record sealed with additionalData, with the key of its key ID, returning its kind and seqnum
+func (c *recordCipher) open(record, additionalData []byte) (plaintext []byte, kind recordKind, seqNum int, err error) {
+	raw := make([]byte, base64.StdEncoding.DecodedLen(len(record)))
+	n, err := base64.StdEncoding.Decode(raw, record)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "invalid encrypted record")
+	}
+	raw = raw[:n]
+
+	if len(raw) < recordHeaderSize || raw[0] != encryptedRecordVersion || len(raw) < recordHeaderSize+int(raw[10]) {
+		return nil, 0, 0, errors.New("invalid encrypted record")
+	}
+	header, raw := raw[:recordHeaderSize+int(raw[10])], raw[recordHeaderSize+int(raw[10]):]
+	kind, seqNum = recordKind(header[1]), int(binary.BigEndian.Uint64(header[2:10]))
+	keyID := string(header[recordHeaderSize:])
+
+	gcm, err := c.keyGCM(keyID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(raw) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, 0, 0, errors.New("invalid encrypted record")
+	}
+
+	plaintext, err = gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], append(append([]byte(nil), header...), additionalData...))
+	if err != nil {
+		return nil, 0, 0, errors.Wrapf(err, "key %v", keyID)
+	}
+	return plaintext, kind, seqNum, nil
+}
+
+type encryptedStoreFactory struct {
+	factory MessageStoreFactory
+	cipher  *recordCipher
+}
+
+//encryptedStore encrypts the messages saved to a MessageStore. Messages are authenticated with the session ID and
+//their seqnum, so that they cannot be moved to another session or seqnum. Records read with GetMessages must be in
+//increasing seqnum order within the range, as their seqnums are not returned by the wrapped store.
+type encryptedStore struct {
+	MessageStore
+	cipher    *recordCipher
+	sessionID []byte
+}
+
+//encryptedIteratingStore is an encryptedStore of a MessageStore implementing MessageIterator
+type encryptedIteratingStore struct {
+	*encryptedStore
+}
+
+//encryptedCreationTimeStore is an encryptedStore of a MessageStore implementing CreationTimeSetter
+type encryptedCreationTimeStore struct {
+	*encryptedStore
+}
+
+//encryptedIteratingCreationTimeStore is an encryptedStore of a MessageStore implementing MessageIterator and
+//CreationTimeSetter
+type encryptedIteratingCreationTimeStore struct {
+	encryptedIteratingStore
+}
+
+//NewEncryptedStoreFactory wraps factory to encrypt messages with AES-GCM, with the keys of keys, before they are
+//saved, and to decrypt them when they are read back. Seqnums and the creation time are stored unencrypted. The
+//stores of factory must not hold unencrypted messages, e.g. they are reset when encryption is turned on.
+func NewEncryptedStoreFactory(factory MessageStoreFactory, keys KeyProvider) MessageStoreFactory {
+	return encryptedStoreFactory{factory: factory, cipher: newRecordCipher(keys)}
+}
+
+func (f encryptedStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	store, err := f.factory.Create(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &encryptedStore{MessageStore: store, cipher: f.cipher, sessionID: []byte(sessionID.String())}
+	_, iterating := store.(MessageIterator)
+	_, creationTimeSetter := store.(CreationTimeSetter)
+	switch {
+	case iterating && creationTimeSetter:
+		return encryptedIteratingCreationTimeStore{encryptedIteratingStore{s}}, nil
+	case iterating:
+		return encryptedIteratingStore{s}, nil
+	case creationTimeSetter:
+		return encryptedCreationTimeStore{s}, nil
+	}
+	return s, nil
+}
+
+func (store *encryptedStore) SaveMessage(seqNum int, msg []byte) error {
+	record, err := store.cipher.seal(recordKindStoredMessage, seqNum, msg, store.sessionID)
+	if err != nil {
+		return errors.Wrap(err, "encrypting message")
+	}
+	return store.MessageStore.SaveMessage(seqNum, record)
+}
+
+//open decrypts a record saved by SaveMessage, returning its seqnum
+func (store *encryptedStore) open(record []byte) ([]byte, int, error) {
+	msg, kind, seqNum, err := store.cipher.open(record, store.sessionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kind != recordKindStoredMessage {
+		return nil, 0, fmt.Errorf("unexpected encrypted record kind: %d", kind)
+	}
+	return msg, seqNum, nil
+}
+
+func (store *encryptedStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	records, err := store.MessageStore.GetMessages(beginSeqNum, endSeqNum)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([][]byte, 0, len(records))
+	lastSeqNum := beginSeqNum - 1
+	for _, record := range records {
+		msg, seqNum, err := store.open(record)
+		if err != nil {
+			return nil, errors.Wrap(err, "decrypting message")
+		}
+		if seqNum <= lastSeqNum || seqNum > endSeqNum {
+			return nil, fmt.Errorf("decrypting message: record of seqnum %d out of order after %d", seqNum, lastSeqNum)
+		}
+		lastSeqNum = seqNum
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+//SetCreationTime sets the creation time of the wrapped store, if it is a CreationTimeSetter
+func (store encryptedCreationTimeStore) SetCreationTime(t time.Time) error {
+	return store.MessageStore.(CreationTimeSetter).SetCreationTime(t)
+}
+
+func (store encryptedIteratingCreationTimeStore) SetCreationTime(t time.Time) error {
+	return store.MessageStore.(CreationTimeSetter).SetCreationTime(t)
+}
+
+func (store encryptedIteratingStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
+	return store.MessageStore.(MessageIterator).IterateMessages(beginSeqNum, endSeqNum, func(seqNum int, record []byte) error {
+		msg, recordSeqNum, err := store.open(record)
+		if err != nil {
+			return errors.Wrapf(err, "decrypting message %d", seqNum)
+		}
+		if recordSeqNum != seqNum {
+			return fmt.Errorf("decrypting message %d: record of seqnum %d", seqNum, recordSeqNum)
+		}
+		return fn(seqNum, msg)
+	})
+}
@@ -0,0 +1,249 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func newTestKeyProvider(t *testing.T, currentKeyID string, keyIDs ...string) KeyProvider {
+	keys := make(map[string][]byte)
+	for _, keyID := range keyIDs {
+		keys[keyID] = bytes.Repeat([]byte(keyID[len(keyID)-1:]), 32)
+	}
+
+	keyProvider, err := NewStaticKeyProvider(currentKeyID, keys)
+	require.Nil(t, err)
+	return keyProvider
+}
+
+// EncryptedStoreTestSuite runs all tests in the MessageStoreTestSuite against an encrypted FileStore
+type EncryptedStoreTestSuite struct {
+	MessageStoreTestSuite
+	fileStorePath string
+}
+
+func (suite *EncryptedStoreTestSuite) SetupTest() {
+	suite.fileStorePath = path.Join(os.TempDir(), fmt.Sprintf("EncryptedStoreTestSuite-%d-%d", os.Getpid(), time.Now().UnixNano()))
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.fileStorePath, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	keys := newTestKeyProvider(suite.T(), "key1", "key1")
+	suite.msgStore, err = NewEncryptedStoreFactory(NewFileStoreFactory(settings), keys).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *EncryptedStoreTestSuite) TearDownTest() {
+	suite.msgStore.Close()
+	os.RemoveAll(suite.fileStorePath)
+}
+
+func (suite *EncryptedStoreTestSuite) TestEncryptedAtRest() {
+	msg := []byte("8=FIX.4.4\x0135=D\x011=ACCOUNT\x01")
+	require.Nil(suite.T(), suite.msgStore.SaveMessage(1, msg))
+
+	fnames, err := filepath.Glob(path.Join(suite.fileStorePath, "*.body"))
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), fnames, 1)
+	body, err := ioutil.ReadFile(fnames[0])
+	require.Nil(suite.T(), err)
+	suite.NotEmpty(body)
+	suite.NotContains(string(body), "ACCOUNT")
+
+	msgs, err := suite.msgStore.GetMessages(1, 1)
+	require.Nil(suite.T(), err)
+	suite.Equal([][]byte{msg}, msgs)
+}
+
+func TestEncryptedStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(EncryptedStoreTestSuite))
+}
+
+func TestEncryptedStore_KeyRotation(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	inner, err := NewMemoryStoreFactory().Create(sessionID)
+	require.Nil(t, err)
+	innerFactory := storeFactoryFunc(func(SessionID) (MessageStore, error) { return inner, nil })
+
+	store, err := NewEncryptedStoreFactory(innerFactory, newTestKeyProvider(t, "key1", "key1")).Create(sessionID)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+
+	// records carry the key they are encrypted with
+	store, err = NewEncryptedStoreFactory(innerFactory, newTestKeyProvider(t, "key2", "key1", "key2")).Create(sessionID)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(2, []byte("two")))
+
+	msgs, err := store.GetMessages(1, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("one"), []byte("two")}, msgs)
+
+	// the retired key is needed for the records encrypted with it
+	store, err = NewEncryptedStoreFactory(innerFactory, newTestKeyProvider(t, "key2", "key2")).Create(sessionID)
+	require.Nil(t, err)
+	_, err = store.GetMessages(1, 2)
+	assert.NotNil(t, err)
+	msgs, err = store.GetMessages(2, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("two")}, msgs)
+}
+
+func TestEncryptedStore_Tampered(t *testing.T) {
+	keys := newTestKeyProvider(t, "key1", "key1")
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	inner, err := NewMemoryStoreFactory().Create(sessionID)
+	require.Nil(t, err)
+
+	store, err := NewEncryptedStoreFactory(storeFactoryFunc(func(SessionID) (MessageStore, error) { return inner, nil }), keys).Create(sessionID)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+
+	// records are bound to their session
+	other, err := NewEncryptedStoreFactory(storeFactoryFunc(func(SessionID) (MessageStore, error) { return inner, nil }), keys).
+		Create(SessionID{BeginString: "FIX.4.4", SenderCompID: "OTHER", TargetCompID: "TARGET"})
+	require.Nil(t, err)
+	_, err = other.GetMessages(1, 1)
+	assert.NotNil(t, err)
+
+	records, err := inner.GetMessages(1, 1)
+	require.Nil(t, err)
+	record := append([]byte(nil), records[0]...)
+	record[len(record)/2] ^= 1
+	require.Nil(t, inner.SaveMessage(1, record))
+	_, err = store.GetMessages(1, 1)
+	assert.NotNil(t, err)
+
+	require.Nil(t, inner.SaveMessage(1, []byte("8=FIX.4.4\x01")))
+	err = store.(MessageIterator).IterateMessages(1, 1, func(int, []byte) error { return nil })
+	assert.NotNil(t, err)
+
+	// records are bound to their seqnum
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+	require.Nil(t, store.SaveMessage(2, []byte("two")))
+	records, err = inner.GetMessages(1, 2)
+	require.Nil(t, err)
+	require.Nil(t, inner.SaveMessage(1, records[1]))
+	require.Nil(t, inner.SaveMessage(2, records[0]))
+	_, err = store.GetMessages(1, 2)
+	assert.NotNil(t, err)
+	_, err = store.GetMessages(2, 2)
+	assert.NotNil(t, err)
+	err = store.(MessageIterator).IterateMessages(1, 2, func(int, []byte) error { return nil })
+	assert.NotNil(t, err)
+
+	// log records are not stored messages
+	record, err = newRecordCipher(keys).seal(recordKindIncoming, 1, []byte("one"), []byte(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}.String()))
+	require.Nil(t, err)
+	require.Nil(t, inner.SaveMessage(1, record))
+	_, err = store.GetMessages(1, 1)
+	assert.NotNil(t, err)
+}
+
+// countingKeyProvider counts the calls of a KeyProvider
+type countingKeyProvider struct {
+	KeyProvider
+	currentKeyCalls, keyCalls int
+}
+
+func (p *countingKeyProvider) CurrentKey() (string, []byte, error) {
+	p.currentKeyCalls++
+	return p.KeyProvider.CurrentKey()
+}
+
+func (p *countingKeyProvider) Key(keyID string) ([]byte, error) {
+	p.keyCalls++
+	return p.KeyProvider.Key(keyID)
+}
+
+func TestEncryptedStore_CachesCiphers(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	inner, err := NewMemoryStoreFactory().Create(sessionID)
+	require.Nil(t, err)
+	innerFactory := storeFactoryFunc(func(SessionID) (MessageStore, error) { return inner, nil })
+
+	writeKeys := &countingKeyProvider{KeyProvider: newTestKeyProvider(t, "key1", "key1")}
+	store, err := NewEncryptedStoreFactory(innerFactory, writeKeys).Create(sessionID)
+	require.Nil(t, err)
+	for seqNum := 1; seqNum <= 10; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte("msg")))
+	}
+	assert.Equal(t, 1, writeKeys.currentKeyCalls)
+
+	readKeys := &countingKeyProvider{KeyProvider: newTestKeyProvider(t, "key1", "key1")}
+	store, err = NewEncryptedStoreFactory(innerFactory, readKeys).Create(sessionID)
+	require.Nil(t, err)
+	msgs, err := store.GetMessages(1, 10)
+	require.Nil(t, err)
+	assert.Len(t, msgs, 10)
+	assert.Equal(t, 1, readKeys.keyCalls)
+}
+
+func TestEncryptedStore_OptionalInterfaces(t *testing.T) {
+	keys := newTestKeyProvider(t, "key1", "key1")
+	inner, err := NewMemoryStoreFactory().Create(SessionID{})
+	require.Nil(t, err)
+
+	store, err := NewEncryptedStoreFactory(storeFactoryFunc(func(SessionID) (MessageStore, error) {
+		return getMessagesStore{inner}, nil
+	}), keys).Create(SessionID{})
+	require.Nil(t, err)
+	_, ok := store.(MessageIterator)
+	assert.False(t, ok, "MessageIterator is only forwarded when the wrapped store implements it")
+
+	_, ok = store.(CreationTimeSetter)
+	assert.False(t, ok, "CreationTimeSetter is only forwarded when the wrapped store implements it")
+
+	store, err = NewEncryptedStoreFactory(storeFactoryFunc(func(SessionID) (MessageStore, error) {
+		return inner, nil
+	}), keys).Create(SessionID{})
+	require.Nil(t, err)
+	_, ok = store.(MessageIterator)
+	assert.True(t, ok)
+	creationTime := time.Date(2021, time.June, 1, 2, 3, 4, 0, time.UTC)
+	require.Nil(t, store.(CreationTimeSetter).SetCreationTime(creationTime))
+	assert.Equal(t, creationTime, inner.CreationTime())
+}
+
+func TestNewStaticKeyProvider(t *testing.T) {
+	_, err := NewStaticKeyProvider("key1", map[string][]byte{"key1": make([]byte, 10)})
+	assert.NotNil(t, err)
+	_, err = NewStaticKeyProvider("key2", map[string][]byte{"key1": make([]byte, 16)})
+	assert.NotNil(t, err)
+	_, err = NewStaticKeyProvider("", map[string][]byte{"": make([]byte, 16)})
+	assert.NotNil(t, err)
+
+	keys := map[string][]byte{"key1": make([]byte, 16), "key2": make([]byte, 24)}
+	keyProvider, err := NewStaticKeyProvider("key2", keys)
+	require.Nil(t, err)
+	keyID, key, err := keyProvider.CurrentKey()
+	require.Nil(t, err)
+	assert.Equal(t, "key2", keyID)
+	assert.Len(t, key, 24)
+	_, err = keyProvider.Key("key3")
+	assert.NotNil(t, err)
+}
+
+type storeFactoryFunc func(SessionID) (MessageStore, error)
+
+func (f storeFactoryFunc) Create(sessionID SessionID) (MessageStore, error) {
+	return f(sessionID)
+}
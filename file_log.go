@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path"
 
 	"github.com/quickfixgo/quickfix/config"
 )
@@ -12,6 +11,8 @@ import (
 type fileLog struct {
 	eventLogger   *log.Logger
 	messageLogger *log.Logger
+	eventFile     *rotatingFile
+	messageFile   *rotatingFile
 }
 
 func (l fileLog) OnIncoming(msg []byte) {
@@ -30,13 +31,24 @@ func (l fileLog) OnEventf(format string, v ...interface{}) {
 	l.eventLogger.Printf(format, v...)
 }
 
+//Close closes both log files, returning the first error
+func (l fileLog) Close() error {
+	eventErr := l.eventFile.Close()
+	if err := l.messageFile.Close(); err != nil && eventErr == nil {
+		return err
+	}
+	return eventErr
+}
+
 type fileLogFactory struct {
-	globalLogPath   string
-	sessionLogPaths map[SessionID]string
+	globalLogPath  string
+	globalRotation fileLogRotation
+	settings       *Settings
 }
 
 //NewFileLogFactory creates an instance of LogFactory that writes messages and events to file.
-//The location of global and session log files is configured via FileLogPath.
+//The location of global and session log files is configured via FileLogPath, their rotation via FileLogMaxSize,
+//FileLogRotateAtEndTime, FileLogCompress and FileLogBackupCount.
 func NewFileLogFactory(settings *Settings) (LogFactory, error) {
 	logFactory := fileLogFactory{}
 
@@ -45,41 +57,51 @@ func NewFileLogFactory(settings *Settings) (LogFactory, error) {
 		return logFactory, err
 	}
 
-	logFactory.sessionLogPaths = make(map[SessionID]string)
+	if logFactory.globalRotation, err = newFileLogRotation(settings.GlobalSettings()); err != nil {
+		return logFactory, err
+	}
+
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, err := sessionSettings.Setting(config.FileLogPath); err != nil {
+			return logFactory, err
+		}
 
-	for sid, sessionSettings := range settings.SessionSettings() {
-		logPath, err := sessionSettings.Setting(config.FileLogPath)
-		if err != nil {
+		if _, err := newFileLogRotation(sessionSettings); err != nil {
 			return logFactory, err
 		}
-		logFactory.sessionLogPaths[sid] = logPath
 	}
 
+	//session log paths are looked up on creation, so sessions added to settings later are supported
+	logFactory.settings = settings
+
 	return logFactory, nil
 }
 
 func newFileLog(prefix string, logPath string) (fileLog, error) {
-	l := fileLog{}
+	return newRotatingFileLog(prefix, logPath, fileLogRotation{})
+}
 
-	eventLogName := path.Join(logPath, prefix+".event.current.log")
-	messageLogName := path.Join(logPath, prefix+".messages.current.log")
+func newRotatingFileLog(prefix string, logPath string, rotation fileLogRotation) (fileLog, error) {
+	l := fileLog{}
 
 	if err := os.MkdirAll(logPath, os.ModePerm); err != nil {
 		return l, err
 	}
 
-	fileFlags := os.O_RDWR | os.O_CREATE | os.O_APPEND
-	eventFile, err := os.OpenFile(eventLogName, fileFlags, os.ModePerm)
+	eventFile, err := openRotatingFile(logPath, prefix+".event", rotation)
 	if err != nil {
 		return l, err
 	}
 
-	messageFile, err := os.OpenFile(messageLogName, fileFlags, os.ModePerm)
+	messageFile, err := openRotatingFile(logPath, prefix+".messages", rotation)
 	if err != nil {
+		eventFile.Close()
 		return l, err
 	}
 
 	logFlag := log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC
+	l.eventFile = eventFile
+	l.messageFile = messageFile
 	l.eventLogger = log.New(eventFile, "", logFlag)
 	l.messageLogger = log.New(messageFile, "", logFlag)
 
@@ -87,16 +109,25 @@ func newFileLog(prefix string, logPath string) (fileLog, error) {
 }
 
 func (f fileLogFactory) Create() (Log, error) {
-	return newFileLog("GLOBAL", f.globalLogPath)
+	return newRotatingFileLog("GLOBAL", f.globalLogPath, f.globalRotation)
 }
 
 func (f fileLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
-	logPath, ok := f.sessionLogPaths[sessionID]
-
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
 	if !ok {
 		return nil, fmt.Errorf("logger not defined for %v", sessionID)
 	}
 
+	logPath, err := sessionSettings.Setting(config.FileLogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation, err := newFileLogRotation(sessionSettings)
+	if err != nil {
+		return nil, err
+	}
+
 	prefix := sessionIDFilenamePrefix(sessionID)
-	return newFileLog(prefix, logPath)
+	return newRotatingFileLog(prefix, logPath, rotation)
 }
@@ -0,0 +1,304 @@
+package quickfix
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/internal"
+)
+
+//fileLogRotation configures the rotation of log files
+type fileLogRotation struct {
+	//rotate once the file would exceed maxSize bytes, 0 disables rotation by size
+	maxSize int64
+
+	//rotate on the first write after each end of sessionTime, nil disables rotation by time
+	sessionTime *internal.TimeRange
+
+	//gzip rotated files
+	compress bool
+
+	//number of rotated files kept, 0 keeps all
+	backupCount int
+}
+
+//fileLogRotationTimeFormat sorts rotated files by time of rotation
+const fileLogRotationTimeFormat = "20060102-150405.000000"
+
+func newFileLogRotation(settings *SessionSettings) (rotation fileLogRotation, err error) {
+	if settings.HasSetting(config.FileLogMaxSize) {
+		var maxSize int
+		if maxSize, err = settings.IntSetting(config.FileLogMaxSize); err != nil {
+			return
+		}
+		if maxSize < 0 {
+			err = errors.New("FileLogMaxSize must not be negative")
+			return
+		}
+		rotation.maxSize = int64(maxSize)
+	}
+
+	if settings.HasSetting(config.FileLogRotateAtEndTime) {
+		var rotateAtEndTime bool
+		if rotateAtEndTime, err = settings.BoolSetting(config.FileLogRotateAtEndTime); err != nil {
+			return
+		}
+
+		if rotateAtEndTime {
+			if rotation.sessionTime, err = sessionTimeRange(settings); err != nil {
+				return
+			}
+			if rotation.sessionTime == nil {
+				err = errors.New("FileLogRotateAtEndTime requires StartTime and EndTime")
+				return
+			}
+		}
+	}
+
+	if settings.HasSetting(config.FileLogCompress) {
+		if rotation.compress, err = settings.BoolSetting(config.FileLogCompress); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.FileLogBackupCount) {
+		if rotation.backupCount, err = settings.IntSetting(config.FileLogBackupCount); err != nil {
+			return
+		}
+		if rotation.backupCount < 0 {
+			err = errors.New("FileLogBackupCount must not be negative")
+		}
+	}
+
+	return
+}
+
+//rotatingFile appends to <name>.current.log, renaming it to <name>.<time>.log when rotated
+type rotatingFile struct {
+	mutex    sync.Mutex
+	dir      string
+	name     string
+	rotation fileLogRotation
+	file     *os.File
+	size     int64
+
+	//time of the first write in the session time range to the current file, zero until a write falls in the range
+	periodStart time.Time
+
+	//rotated files waiting to be compressed and pruned, in order, by a single worker so that pruning never removes a
+	//file that is being compressed
+	rotated chan string
+	worker  sync.WaitGroup
+
+	now func() time.Time
+}
+
+func openRotatingFile(dir, name string, rotation fileLogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{dir: dir, name: name, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) currentPath() string {
+	return path.Join(f.dir, f.name+".current.log")
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.currentPath(), os.O_RDWR|os.O_CREATE|os.O_APPEND, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+//updatePeriod starts tracking the session time range once a write falls in it
+func (f *rotatingFile) updatePeriod(now time.Time) {
+	if f.rotation.sessionTime != nil && f.periodStart.IsZero() && f.rotation.sessionTime.IsInRange(now) {
+		f.periodStart = now
+	}
+}
+
+func (f *rotatingFile) shouldRotate(now time.Time, n int) bool {
+	if f.rotation.maxSize > 0 && f.size > 0 && f.size+int64(n) > f.rotation.maxSize {
+		return true
+	}
+
+	return !f.periodStart.IsZero() && !f.rotation.sessionTime.IsInSameRange(f.periodStart, now)
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	if f.shouldRotate(now, len(p)) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	f.updatePeriod(now)
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+//rotate renames the current file and opens a new one
+func (f *rotatingFile) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := f.rotatedPath(now)
+	if err := os.Rename(f.currentPath(), rotated); err != nil {
+		return err
+	}
+
+	f.periodStart = time.Time{}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.rotated == nil {
+		f.rotated = make(chan string, 16)
+		f.worker.Add(1)
+		go f.processRotated(f.rotated)
+	}
+	f.rotated <- rotated
+
+	return nil
+}
+
+//rotatedPath returns the name of a file rotated at now, later by a microsecond for each file of an earlier rotation
+//in the same microsecond
+func (f *rotatingFile) rotatedPath(now time.Time) string {
+	for {
+		rotated := path.Join(f.dir, f.name+"."+now.UTC().Format(fileLogRotationTimeFormat)+".log")
+		if !fileExists(rotated) && !fileExists(rotated+".gz") {
+			return rotated
+		}
+		now = now.Add(time.Microsecond)
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+//processRotated compresses each rotated file and prunes the backups after it, until rotated is closed
+func (f *rotatingFile) processRotated(rotated <-chan string) {
+	defer f.worker.Done()
+
+	for name := range rotated {
+		if f.rotation.compress {
+			_ = gzipFile(name)
+		}
+		f.removeBackups()
+	}
+}
+
+//removeBackups removes the oldest rotated files beyond the backup count
+func (f *rotatingFile) removeBackups() {
+	if f.rotation.backupCount == 0 {
+		return
+	}
+
+	backups, err := f.backups()
+	if err != nil || len(backups) <= f.rotation.backupCount {
+		return
+	}
+
+	for _, backup := range backups[:len(backups)-f.rotation.backupCount] {
+		_ = os.Remove(path.Join(f.dir, backup))
+	}
+}
+
+//backups returns the names of rotated files, oldest first
+func (f *rotatingFile) backups() ([]string, error) {
+	infos, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	current := path.Base(f.currentPath())
+	for _, info := range infos {
+		name := info.Name()
+		if name == current || !strings.HasPrefix(name, f.name+".") {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz") {
+			backups = append(backups, name)
+		}
+	}
+
+	sort.Strings(backups)
+	return backups, nil
+}
+
+//Close closes the current file, waiting for the compression and pruning of rotated files
+func (f *rotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.rotated != nil {
+		close(f.rotated)
+		f.rotated = nil
+	}
+	f.worker.Wait()
+	return f.file.Close()
+}
+
+//gzipFile compresses name to name.gz, removing name
+func gzipFile(name string) (err error) {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+
+	in.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,159 @@
+package quickfix
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRotatingFileTest(t *testing.T, rotation fileLogRotation) (*rotatingFile, string) {
+	dir, err := ioutil.TempDir("", "TestRotatingFile")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	f, err := openRotatingFile(dir, "prefix.messages", rotation)
+	require.Nil(t, err)
+
+	return f, dir
+}
+
+func TestRotatingFile_MaxSize(t *testing.T) {
+	f, dir := newRotatingFileTest(t, fileLogRotation{maxSize: 10})
+
+	clock := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	f.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err := f.Write([]byte(line))
+		require.Nil(t, err)
+	}
+	require.Nil(t, f.Close())
+
+	backups, err := f.backups()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"prefix.messages.20230102-150407.000000.log", "prefix.messages.20230102-150408.000000.log"}, backups)
+
+	current, err := ioutil.ReadFile(path.Join(dir, "prefix.messages.current.log"))
+	require.Nil(t, err)
+	assert.Equal(t, "third\n", string(current))
+
+	rotated, err := ioutil.ReadFile(path.Join(dir, backups[0]))
+	require.Nil(t, err)
+	assert.Equal(t, "first\n", string(rotated))
+}
+
+func TestRotatingFile_CompressAndBackupCount(t *testing.T) {
+	f, dir := newRotatingFileTest(t, fileLogRotation{maxSize: 1, compress: true, backupCount: 2})
+
+	clock := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	f.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		_, err := f.Write([]byte(line))
+		require.Nil(t, err)
+	}
+	require.Nil(t, f.Close())
+
+	backups, err := f.backups()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"prefix.messages.20230102-150408.000000.log.gz", "prefix.messages.20230102-150409.000000.log.gz"}, backups)
+
+	gzFile, err := os.Open(path.Join(dir, backups[1]))
+	require.Nil(t, err)
+	defer gzFile.Close()
+	gz, err := gzip.NewReader(gzFile)
+	require.Nil(t, err)
+	content, err := ioutil.ReadAll(gz)
+	require.Nil(t, err)
+	assert.Equal(t, "3\n", string(content))
+}
+
+func TestRotatingFile_RotateQuickly(t *testing.T) {
+	f, dir := newRotatingFileTest(t, fileLogRotation{maxSize: 1, compress: true, backupCount: 3})
+
+	// large enough that rotated files are still compressed while the next rotations happen
+	padding := strings.Repeat("x", 64*1024)
+	for i := 0; i < 200; i++ {
+		_, err := f.Write([]byte(fmt.Sprintf("%d %s\n", i, padding)))
+		require.Nil(t, err)
+	}
+	require.Nil(t, f.Close())
+
+	backups, err := f.backups()
+	require.Nil(t, err)
+	require.Len(t, backups, 3)
+
+	for i, backup := range backups {
+		assert.True(t, strings.HasSuffix(backup, ".log.gz"), backup)
+
+		gzFile, err := os.Open(path.Join(dir, backup))
+		require.Nil(t, err)
+		gz, err := gzip.NewReader(gzFile)
+		require.Nil(t, err)
+		content, err := ioutil.ReadAll(gz)
+		gzFile.Close()
+		require.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("%d %s\n", 196+i, padding), string(content))
+	}
+}
+
+func TestRotatingFile_EndTime(t *testing.T) {
+	sessionTime := internal.NewUTCTimeRange(internal.NewTimeOfDay(9, 0, 0), internal.NewTimeOfDay(17, 0, 0))
+	f, _ := newRotatingFileTest(t, fileLogRotation{sessionTime: sessionTime})
+
+	for _, now := range []time.Time{
+		time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 2, 16, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 2, 18, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 2, 19, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 3, 10, 0, 0, 0, time.UTC),
+	} {
+		now := now
+		f.now = func() time.Time { return now }
+		_, err := f.Write([]byte("line\n"))
+		require.Nil(t, err)
+	}
+	require.Nil(t, f.Close())
+
+	backups, err := f.backups()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"prefix.messages.20230102-180000.000000.log"}, backups, "should rotate once after EndTime")
+}
+
+func TestNewFileLogRotation(t *testing.T) {
+	settings := NewSessionSettings()
+	rotation, err := newFileLogRotation(settings)
+	require.Nil(t, err)
+	assert.Equal(t, fileLogRotation{}, rotation)
+
+	settings.Set(config.FileLogMaxSize, "1024")
+	settings.Set(config.FileLogCompress, "Y")
+	settings.Set(config.FileLogBackupCount, "5")
+	rotation, err = newFileLogRotation(settings)
+	require.Nil(t, err)
+	assert.Equal(t, fileLogRotation{maxSize: 1024, compress: true, backupCount: 5}, rotation)
+
+	settings.Set(config.FileLogRotateAtEndTime, "Y")
+	_, err = newFileLogRotation(settings)
+	assert.NotNil(t, err, "rotation at EndTime should require StartTime and EndTime")
+
+	settings.Set(config.StartTime, "09:00:00")
+	settings.Set(config.EndTime, "17:00:00")
+	rotation, err = newFileLogRotation(settings)
+	require.Nil(t, err)
+	assert.NotNil(t, rotation.sessionTime)
+
+	settings.Set(config.FileLogMaxSize, "-1")
+	_, err = newFileLogRotation(settings)
+	assert.NotNil(t, err)
+}
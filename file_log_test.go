@@ -128,3 +128,21 @@ func TestFileLog_Append(t *testing.T) {
 		t.Error("Unexpected EOF")
 	}
 }
+
+func TestFileLog_Close(t *testing.T) {
+	helper := newFileLogHelper(t)
+	log := helper.Log.(fileLog)
+
+	//a failure closing the event file does not leave the message file open
+	if err := log.eventFile.file.Close(); err != nil {
+		t.Error("Unexpected error", err)
+	}
+
+	if err := log.Close(); err == nil {
+		t.Error("Should expect error closing the closed event file")
+	}
+
+	if _, err := log.messageFile.file.Write([]byte("incoming")); err == nil {
+		t.Error("Should expect the message file to be closed")
+	}
+}
@@ -1,11 +1,13 @@
 package quickfix
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"time"
 
@@ -13,6 +15,9 @@ import (
 	"github.com/quickfixgo/quickfix/config"
 )
 
+// seqNumWidth is the width of the zero padded seqnum files, a seqnum file of another size was torn
+const seqNumWidth = 19
+
 type msgDef struct {
 	offset int64
 	size   int
@@ -36,6 +41,15 @@ type fileStore struct {
 	sessionFile        *os.File
 	senderSeqNumsFile  *os.File
 	targetSeqNumsFile  *os.File
+	dirname            string
+
+	// move the files to a dated directory under archive on reset, rather than deleting them
+	archive bool
+
+	// number of sequence numbers of messages kept when compacting, 0 disables compaction
+	retainSeqNums int
+
+	syncer *fileStoreSyncer
 }
 
 // NewFileStoreFactory returns a file-based implementation of MessageStoreFactory
@@ -53,7 +67,15 @@ func (f fileStoreFactory) Create(sessionID SessionID) (msgStore MessageStore, er
 	if err != nil {
 		return nil, err
 	}
-	return newFileStore(sessionID, dirname)
+	store, err := newFileStore(sessionID, dirname)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.configure(sessionSettings); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
 }
 
 func newFileStore(sessionID SessionID, dirname string) (*fileStore, error) {
@@ -65,6 +87,7 @@ func newFileStore(sessionID SessionID, dirname string) (*fileStore, error) {
 
 	store := &fileStore{
 		sessionID:          sessionID,
+		dirname:            dirname,
 		cache:              &memoryStore{},
 		offsets:            make(map[int]msgDef),
 		bodyFname:          path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "body")),
@@ -72,6 +95,7 @@ func newFileStore(sessionID SessionID, dirname string) (*fileStore, error) {
 		sessionFname:       path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "session")),
 		senderSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "senderseqnums")),
 		targetSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "targetseqnums")),
+		syncer:             &fileStoreSyncer{dirty: make(map[*os.File]bool)},
 	}
 
 	if err := store.Refresh(); err != nil {
@@ -81,33 +105,85 @@ func newFileStore(sessionID SessionID, dirname string) (*fileStore, error) {
 	return store, nil
 }
 
-// Reset deletes the store files and sets the seqnums back to 1
+// configure applies the FileStoreArchive, FileStoreRetainSeqNums and FileStoreSync settings
+func (store *fileStore) configure(settings *SessionSettings) (err error) {
+	syncer, err := newFileStoreSyncer(settings)
+	if err != nil {
+		return
+	}
+	store.syncer = syncer
+
+	if settings.HasSetting(config.FileStoreArchive) {
+		if store.archive, err = settings.BoolSetting(config.FileStoreArchive); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.FileStoreRetainSeqNums) {
+		if store.retainSeqNums, err = settings.IntSetting(config.FileStoreRetainSeqNums); err != nil {
+			return
+		}
+		if store.retainSeqNums < 0 {
+			return errors.New("FileStoreRetainSeqNums must not be negative")
+		}
+	}
+
+	return
+}
+
+// Reset deletes the store files, or archives them if configured, and sets the seqnums back to 1
 func (store *fileStore) Reset() error {
+	archiveDir := store.archiveDir()
+
 	if err := store.cache.Reset(); err != nil {
 		return errors.Wrap(err, "cache reset")
 	}
 
-	if err := store.Close(); err != nil {
+	if err := store.closeFiles(); err != nil {
 		return errors.Wrap(err, "close")
 	}
-	if err := removeFile(store.bodyFname); err != nil {
+	if err := store.recoverCompaction(); err != nil {
+		return err
+	}
+
+	removeOrArchive := removeFile
+	if store.archive {
+		if err := os.MkdirAll(archiveDir, os.ModePerm); err != nil {
+			return errors.Wrap(err, "archive")
+		}
+		removeOrArchive = func(fname string) error { return moveFile(fname, archiveDir) }
+	}
+
+	if err := removeOrArchive(store.bodyFname); err != nil {
 		return err
 	}
-	if err := removeFile(store.headerFname); err != nil {
+	if err := removeOrArchive(store.headerFname); err != nil {
 		return err
 	}
-	if err := removeFile(store.sessionFname); err != nil {
+	if err := removeOrArchive(store.sessionFname); err != nil {
 		return err
 	}
-	if err := removeFile(store.senderSeqNumsFname); err != nil {
+	if err := removeOrArchive(store.senderSeqNumsFname); err != nil {
 		return err
 	}
-	if err := removeFile(store.targetSeqNumsFname); err != nil {
+	if err := removeOrArchive(store.targetSeqNumsFname); err != nil {
 		return err
 	}
 	return store.Refresh()
 }
 
+// archiveDir returns the directory the store files are archived to, dated by the creation time of the store.
+// A numbered suffix is added when the directory already exists, e.g. for resets within the same second.
+func (store *fileStore) archiveDir() string {
+	dir := path.Join(store.dirname, "archive", store.cache.CreationTime().UTC().Format("20060102-150405"))
+	for i, uniqueDir := 1, dir; ; i++ {
+		if !fileExists(uniqueDir) {
+			return uniqueDir
+		}
+		uniqueDir = fmt.Sprintf("%s-%d", dir, i)
+	}
+}
+
 // Refresh closes the store files and then reloads from them
 func (store *fileStore) Refresh() (err error) {
 	if err = store.cache.Reset(); err != nil {
@@ -115,10 +191,14 @@ func (store *fileStore) Refresh() (err error) {
 		return
 	}
 
-	if err = store.Close(); err != nil {
+	if err = store.closeFiles(); err != nil {
+		return err
+	}
+	if err = store.recoverCompaction(); err != nil {
 		return err
 	}
 
+	store.offsets = make(map[int]msgDef)
 	creationTimePopulated, err := store.populateCache()
 	if err != nil {
 		return err
@@ -157,16 +237,8 @@ func (store *fileStore) Refresh() (err error) {
 }
 
 func (store *fileStore) populateCache() (creationTimePopulated bool, err error) {
-	if tmpHeaderFile, err := os.Open(store.headerFname); err == nil {
-		defer tmpHeaderFile.Close()
-		for {
-			var seqNum, size int
-			var offset int64
-			if cnt, err := fmt.Fscanf(tmpHeaderFile, "%d,%d,%d\n", &seqNum, &offset, &size); err != nil || cnt != 3 {
-				break
-			}
-			store.offsets[seqNum] = msgDef{offset: offset, size: size}
-		}
+	if err := store.populateOffsets(); err != nil {
+		return false, err
 	}
 
 	if timeBytes, err := ioutil.ReadFile(store.sessionFname); err == nil {
@@ -177,11 +249,13 @@ func (store *fileStore) populateCache() (creationTimePopulated bool, err error)
 		}
 	}
 
+	senderSeqNumRead := false
 	if senderSeqNumBytes, err := ioutil.ReadFile(store.senderSeqNumsFname); err == nil {
 		if senderSeqNum, err := strconv.Atoi(string(senderSeqNumBytes)); err == nil {
 			if err = store.cache.SetNextSenderMsgSeqNum(senderSeqNum); err != nil {
 				return creationTimePopulated, errors.Wrap(err, "cache set next sender")
 			}
+			senderSeqNumRead = len(senderSeqNumBytes) == seqNumWidth
 		}
 	}
 
@@ -193,9 +267,65 @@ func (store *fileStore) populateCache() (creationTimePopulated bool, err error)
 		}
 	}
 
+	// a torn or missing seqnum file must not send a MsgSeqNum that was already used by a stored message. A seqnum
+	// file that was read whole is kept, it may have been set below the stored messages on purpose.
+	if !senderSeqNumRead {
+		for seqNum := range store.offsets {
+			if seqNum >= store.cache.NextSenderMsgSeqNum() {
+				if err = store.cache.SetNextSenderMsgSeqNum(seqNum + 1); err != nil {
+					return creationTimePopulated, errors.Wrap(err, "cache set next sender")
+				}
+			}
+		}
+	}
+
 	return creationTimePopulated, nil
 }
 
+// populateOffsets reads the header file. Records after a torn or incomplete record, e.g. a header line that was
+// partially written or refers past the end of the body file when the host crashed, are dropped and truncated from
+// the header file so new records are appended after the last complete one.
+func (store *fileStore) populateOffsets() error {
+	header, err := ioutil.ReadFile(store.headerFname)
+	if err != nil {
+		return nil
+	}
+
+	var bodySize int64
+	if info, err := os.Stat(store.bodyFname); err == nil {
+		bodySize = info.Size()
+	}
+
+	validLen := 0
+	for validLen < len(header) {
+		end := bytes.IndexByte(header[validLen:], '\n')
+		if end < 0 {
+			break
+		}
+
+		var seqNum, size int
+		var offset int64
+		line := string(header[validLen : validLen+end])
+		if cnt, err := fmt.Sscanf(line, "%d,%d,%d", &seqNum, &offset, &size); err != nil || cnt != 3 {
+			break
+		}
+		if offset < 0 || size < 0 || offset+int64(size) > bodySize {
+			break
+		}
+
+		store.offsets[seqNum] = msgDef{offset: offset, size: size}
+		validLen += end + 1
+	}
+
+	if validLen < len(header) {
+		if err := os.Truncate(store.headerFname, int64(validLen)); err != nil {
+			return errors.Wrap(err, "truncate torn header")
+		}
+	}
+
+	return nil
+}
+
 func (store *fileStore) setSession() error {
 	if _, err := store.sessionFile.Seek(0, io.SeekStart); err != nil {
 		return fmt.Errorf("unable to rewind file: %s: %s", store.sessionFname, err.Error())
@@ -218,13 +348,10 @@ func (store *fileStore) setSeqNum(f *os.File, seqNum int) error {
 	if _, err := f.Seek(0, io.SeekStart); err != nil {
 		return fmt.Errorf("unable to rewind file: %s: %s", f.Name(), err.Error())
 	}
-	if _, err := fmt.Fprintf(f, "%019d", seqNum); err != nil {
+	if _, err := fmt.Fprintf(f, "%0*d", seqNumWidth, seqNum); err != nil {
 		return fmt.Errorf("unable to write to file: %s: %s", f.Name(), err.Error())
 	}
-	if err := f.Sync(); err != nil {
-		return fmt.Errorf("unable to flush file: %s: %s", f.Name(), err.Error())
-	}
-	return nil
+	return store.syncer.written(f)
 }
 
 // NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent
@@ -274,6 +401,17 @@ func (store *fileStore) CreationTime() time.Time {
 	return store.cache.CreationTime()
 }
 
+// SetCreationTime sets the creation time of the store
+func (store *fileStore) SetCreationTime(t time.Time) error {
+	if err := store.sessionFile.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate file: %s: %s", store.sessionFname, err.Error())
+	}
+	store.cache.creationTime = t
+	return store.setSession()
+}
+
+// SaveMessage appends msg to the store files. When compaction is enabled, the save that doubles the retained
+// messages also compacts the files inline, so that save copies the retained messages before it returns.
 func (store *fileStore) SaveMessage(seqNum int, msg []byte) error {
 	offset, err := store.bodyFile.Seek(0, os.SEEK_END)
 	if err != nil {
@@ -282,24 +420,155 @@ func (store *fileStore) SaveMessage(seqNum int, msg []byte) error {
 	if _, err := store.headerFile.Seek(0, os.SEEK_END); err != nil {
 		return fmt.Errorf("unable to seek to end of file: %s: %s", store.headerFname, err.Error())
 	}
+
+	// the body is written ahead of the header, so a header record never refers to a body that was not written
+	if _, err := store.bodyFile.Write(msg); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", store.bodyFname, err.Error())
+	}
 	if _, err := fmt.Fprintf(store.headerFile, "%d,%d,%d\n", seqNum, offset, len(msg)); err != nil {
 		return fmt.Errorf("unable to write to file: %s: %s", store.headerFname, err.Error())
 	}
 
 	store.offsets[seqNum] = msgDef{offset: offset, size: len(msg)}
 
-	if _, err := store.bodyFile.Write(msg); err != nil {
-		return fmt.Errorf("unable to write to file: %s: %s", store.bodyFname, err.Error())
-	}
-	if err := store.bodyFile.Sync(); err != nil {
-		return fmt.Errorf("unable to flush file: %s: %s", store.bodyFname, err.Error())
+	if err := store.syncer.written(store.bodyFile, store.headerFile); err != nil {
+		return err
 	}
-	if err := store.headerFile.Sync(); err != nil {
-		return fmt.Errorf("unable to flush file: %s: %s", store.headerFname, err.Error())
+
+	// compact once the retained messages have doubled, so the cost of copying is amortized
+	if store.retainSeqNums > 0 && len(store.offsets) >= 2*store.retainSeqNums {
+		return store.compact(seqNum - store.retainSeqNums + 1)
 	}
 	return nil
 }
 
+// compactFnames returns the names the compacted body and header files are written to
+func (store *fileStore) compactFnames() (bodyFname, headerFname string) {
+	return store.bodyFname + ".compact", store.headerFname + ".compact"
+}
+
+// compact rewrites the body and header files without the messages before beginSeqNum. The compaction is committed by
+// renaming the compacted body over the body, a crash before the compacted header is renamed as well is completed by
+// recoverCompaction when the store is opened.
+func (store *fileStore) compact(beginSeqNum int) error {
+	var seqNums []int
+	for seqNum := range store.offsets {
+		if seqNum >= beginSeqNum {
+			seqNums = append(seqNums, seqNum)
+		}
+	}
+	sort.Ints(seqNums)
+
+	tmpBodyFname, tmpHeaderFname := store.compactFnames()
+	offsets, err := store.writeCompacted(seqNums, tmpBodyFname, tmpHeaderFname)
+	if err == nil {
+		err = store.syncer.flush()
+	}
+	if err != nil {
+		removeFile(tmpBodyFname)
+		removeFile(tmpHeaderFname)
+		return errors.Wrap(err, "compact")
+	}
+
+	closeErr := closeFile(store.bodyFile)
+	if err := closeFile(store.headerFile); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	if closeErr == nil {
+		closeErr = os.Rename(tmpBodyFname, store.bodyFname)
+	}
+	if closeErr != nil {
+		removeFile(tmpBodyFname)
+		removeFile(tmpHeaderFname)
+		return store.reopenCompacted(store.offsets, store.headerFname, errors.Wrap(closeErr, "compact"))
+	}
+
+	// committed, the directory is synced so the body is renamed ahead of the header on disk
+	headerFname := store.headerFname
+	if err = syncDir(store.dirname); err == nil {
+		err = os.Rename(tmpHeaderFname, store.headerFname)
+	}
+	if err == nil {
+		err = syncDir(store.dirname)
+	} else {
+		// records are appended to the compacted header until recoverCompaction renames it
+		headerFname = tmpHeaderFname
+	}
+
+	return store.reopenCompacted(offsets, headerFname, errors.Wrap(err, "compact"))
+}
+
+// reopenCompacted opens the body and header files after compact closed them, with the offsets of the body. err is
+// returned unless the files cannot be opened.
+func (store *fileStore) reopenCompacted(offsets map[int]msgDef, headerFname string, err error) (openErr error) {
+	store.offsets = offsets
+	if store.bodyFile, openErr = openOrCreateFile(store.bodyFname, 0660); openErr != nil {
+		return
+	}
+	if store.headerFile, openErr = openOrCreateFile(headerFname, 0660); openErr != nil {
+		return
+	}
+	return err
+}
+
+// recoverCompaction completes a compaction that was committed by renaming the compacted body, but interrupted before
+// the compacted header was renamed, and removes the files of a compaction that was not committed
+func (store *fileStore) recoverCompaction() error {
+	tmpBodyFname, tmpHeaderFname := store.compactFnames()
+	if !fileExists(tmpHeaderFname) || fileExists(tmpBodyFname) {
+		if err := removeFile(tmpBodyFname); err != nil {
+			return err
+		}
+		return removeFile(tmpHeaderFname)
+	}
+
+	if err := os.Rename(tmpHeaderFname, store.headerFname); err != nil {
+		return errors.Wrap(err, "recover compaction")
+	}
+	return syncDir(store.dirname)
+}
+
+// writeCompacted copies the messages of seqNums to new body and header files
+func (store *fileStore) writeCompacted(seqNums []int, bodyFname, headerFname string) (offsets map[int]msgDef, err error) {
+	bodyFile, err := os.OpenFile(bodyFname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return nil, err
+	}
+	defer bodyFile.Close()
+
+	headerFile, err := os.OpenFile(headerFname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return nil, err
+	}
+	defer headerFile.Close()
+
+	offsets = make(map[int]msgDef, len(seqNums))
+	var offset int64
+	for _, seqNum := range seqNums {
+		msg, _, err := store.getMessage(seqNum)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := bodyFile.Write(msg); err != nil {
+			return nil, err
+		}
+		if _, err := fmt.Fprintf(headerFile, "%d,%d,%d\n", seqNum, offset, len(msg)); err != nil {
+			return nil, err
+		}
+
+		offsets[seqNum] = msgDef{offset: offset, size: len(msg)}
+		offset += int64(len(msg))
+	}
+
+	if err := bodyFile.Sync(); err != nil {
+		return nil, err
+	}
+	if err := headerFile.Sync(); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
 func (store *fileStore) getMessage(seqNum int) (msg []byte, found bool, err error) {
 	msgInfo, found := store.offsets[seqNum]
 	if !found {
@@ -314,22 +583,46 @@ func (store *fileStore) getMessage(seqNum int) (msg []byte, found bool, err erro
 	return msg, true, nil
 }
 
-func (store *fileStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
-	var msgs [][]byte
+// IterateMessages reads the messages of the range one at a time
+func (store *fileStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
 	for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
 		m, found, err := store.getMessage(seqNum)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if found {
-			msgs = append(msgs, m)
+			if err := fn(seqNum, m); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
+
+func (store *fileStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	if err := store.IterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 	return msgs, nil
 }
 
-// Close closes the store's files
+// Close syncs pending writes and closes the store's files
 func (store *fileStore) Close() error {
+	if err := store.syncer.close(); err != nil {
+		return err
+	}
+	return store.closeFiles()
+}
+
+// closeFiles syncs pending writes and closes the store's files, they are reopened by Refresh
+func (store *fileStore) closeFiles() error {
+	if err := store.syncer.flush(); err != nil {
+		return err
+	}
 	if err := closeFile(store.bodyFile); err != nil {
 		return err
 	}
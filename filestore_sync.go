@@ -0,0 +1,172 @@
+package quickfix
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// fileStoreSyncPolicy decides when the filestore fsyncs its writes
+type fileStoreSyncPolicy int
+
+const (
+	// fsync each write before returning
+	fileStoreSyncAlways fileStoreSyncPolicy = iota
+
+	// group commit, fsync once a batch of writes is pending or on an interval
+	fileStoreSyncBatch
+
+	// leave flushing to the operating system
+	fileStoreSyncNever
+)
+
+// defaultFileStoreSyncInterval is the group commit interval of the BATCH policy if neither interval nor batch size are set
+const defaultFileStoreSyncInterval = time.Second
+
+// fileStoreSyncer fsyncs the files written by a filestore according to its policy
+type fileStoreSyncer struct {
+	policy    fileStoreSyncPolicy
+	batchSize int
+
+	mutex   sync.Mutex
+	dirty   map[*os.File]bool
+	pending int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newFileStoreSyncer(settings *SessionSettings) (*fileStoreSyncer, error) {
+	s := &fileStoreSyncer{dirty: make(map[*os.File]bool)}
+
+	if !settings.HasSetting(config.FileStoreSync) {
+		return s, nil
+	}
+
+	policy, err := settings.Setting(config.FileStoreSync)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToUpper(policy) {
+	case "ALWAYS":
+		s.policy = fileStoreSyncAlways
+	case "BATCH":
+		s.policy = fileStoreSyncBatch
+	case "NEVER":
+		s.policy = fileStoreSyncNever
+	default:
+		return nil, IncorrectFormatForSetting{Setting: config.FileStoreSync, Value: policy}
+	}
+
+	if s.policy != fileStoreSyncBatch {
+		return s, nil
+	}
+
+	if settings.HasSetting(config.FileStoreSyncBatchSize) {
+		if s.batchSize, err = settings.IntSetting(config.FileStoreSyncBatchSize); err != nil {
+			return nil, err
+		}
+		if s.batchSize < 0 {
+			return nil, fmt.Errorf("FileStoreSyncBatchSize must not be negative")
+		}
+	}
+
+	var interval time.Duration
+	if settings.HasSetting(config.FileStoreSyncInterval) {
+		if interval, err = settings.DurationSetting(config.FileStoreSyncInterval); err != nil {
+			return nil, err
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("FileStoreSyncInterval must not be negative")
+		}
+	} else if s.batchSize == 0 {
+		interval = defaultFileStoreSyncInterval
+	}
+
+	if interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.run(interval)
+	}
+
+	return s, nil
+}
+
+func (s *fileStoreSyncer) run(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// written records writes to files, syncing them as required by the policy
+func (s *fileStoreSyncer) written(files ...*os.File) error {
+	switch s.policy {
+	case fileStoreSyncAlways:
+		for _, f := range files {
+			if err := f.Sync(); err != nil {
+				return fmt.Errorf("unable to flush file: %s: %s", f.Name(), err.Error())
+			}
+		}
+
+	case fileStoreSyncBatch:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		for _, f := range files {
+			s.dirty[f] = true
+		}
+		s.pending++
+
+		if s.batchSize > 0 && s.pending >= s.batchSize {
+			return s.flushLocked()
+		}
+	}
+
+	return nil
+}
+
+// flush syncs the files with pending writes
+func (s *fileStoreSyncer) flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.flushLocked()
+}
+
+func (s *fileStoreSyncer) flushLocked() (err error) {
+	for f := range s.dirty {
+		if syncErr := f.Sync(); syncErr != nil && err == nil {
+			err = fmt.Errorf("unable to flush file: %s: %s", f.Name(), syncErr.Error())
+		}
+		delete(s.dirty, f)
+	}
+	s.pending = 0
+
+	return
+}
+
+// close stops the group commit interval after a final flush
+func (s *fileStoreSyncer) close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+
+	return s.flush()
+}
@@ -2,12 +2,14 @@ package quickfix
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -47,3 +49,279 @@ func (suite *FileStoreTestSuite) TearDownTest() {
 func TestFileStoreTestSuite(t *testing.T) {
 	suite.Run(t, new(FileStoreTestSuite))
 }
+
+func newFileStoreWithSettings(t *testing.T, extraSettings string) (*fileStore, string) {
+	fileStorePath := path.Join(os.TempDir(), fmt.Sprintf("FileStoreTest-%d-%d", os.Getpid(), time.Now().UnixNano()))
+	t.Cleanup(func() { os.RemoveAll(fileStorePath) })
+
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, fileStorePath, extraSettings, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(t, err)
+
+	store, err := NewFileStoreFactory(settings).Create(sessionID)
+	require.Nil(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store.(*fileStore), fileStorePath
+}
+
+func TestFileStore_ResetArchive(t *testing.T) {
+	store, fileStorePath := newFileStoreWithSettings(t, "FileStoreArchive=Y")
+
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	archiveDir := store.archiveDir()
+
+	require.Nil(t, store.Reset())
+	assert.Equal(t, 1, store.NextSenderMsgSeqNum())
+
+	msgs, err := store.GetMessages(1, 1)
+	require.Nil(t, err)
+	assert.Empty(t, msgs)
+
+	body, err := ioutil.ReadFile(path.Join(archiveDir, "FIX.4.4-SENDER-TARGET.body"))
+	require.Nil(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	seqNums, err := ioutil.ReadFile(path.Join(archiveDir, "FIX.4.4-SENDER-TARGET.senderseqnums"))
+	require.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("%019d", 2), string(seqNums))
+	assert.True(t, strings.HasPrefix(archiveDir, path.Join(fileStorePath, "archive")))
+}
+
+func TestFileStore_ResetArchiveTwice(t *testing.T) {
+	store, _ := newFileStoreWithSettings(t, "FileStoreArchive=Y")
+
+	// resets within the same second archive to separate directories
+	creationTime := time.Date(2023, time.January, 2, 8, 0, 0, 0, time.UTC)
+	var archiveDirs []string
+	for _, msg := range []string{"one", "two"} {
+		require.Nil(t, store.SetCreationTime(creationTime))
+		require.Nil(t, store.SaveMessage(1, []byte(msg)))
+		archiveDirs = append(archiveDirs, store.archiveDir())
+		require.Nil(t, store.Reset())
+	}
+	assert.NotEqual(t, archiveDirs[0], archiveDirs[1])
+
+	for i, msg := range []string{"one", "two"} {
+		body, err := ioutil.ReadFile(path.Join(archiveDirs[i], "FIX.4.4-SENDER-TARGET.body"))
+		require.Nil(t, err)
+		assert.Equal(t, msg, string(body))
+	}
+}
+
+func TestFileStore_ResetWithoutArchive(t *testing.T) {
+	store, fileStorePath := newFileStoreWithSettings(t, "")
+
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	require.Nil(t, store.Reset())
+
+	_, err := os.Stat(path.Join(fileStorePath, "archive"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	store, _ := newFileStoreWithSettings(t, "FileStoreRetainSeqNums=3")
+
+	for seqNum := 1; seqNum <= 6; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+
+	expected := [][]byte{[]byte("msg4"), []byte("msg5"), []byte("msg6")}
+	msgs, err := store.GetMessages(1, 6)
+	require.Nil(t, err)
+	assert.Equal(t, expected, msgs)
+
+	info, err := os.Stat(store.bodyFname)
+	require.Nil(t, err)
+	assert.Equal(t, int64(12), info.Size(), "body should only contain the retained messages")
+
+	require.Nil(t, store.SaveMessage(7, []byte("msg7")))
+	require.Nil(t, store.Refresh())
+	msgs, err = store.GetMessages(1, 7)
+	require.Nil(t, err)
+	assert.Equal(t, append(expected, []byte("msg7")), msgs, "offsets should be consistent after reloading")
+}
+
+func TestFileStore_CompactionInterrupted(t *testing.T) {
+	for _, committed := range []bool{false, true} {
+		store, fileStorePath := newFileStoreWithSettings(t, "")
+		for seqNum := 1; seqNum <= 6; seqNum++ {
+			require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		}
+
+		// a crash after the compacted files were written, and after the compacted body was renamed if committed
+		tmpBodyFname, tmpHeaderFname := store.compactFnames()
+		_, err := store.writeCompacted([]int{4, 5, 6}, tmpBodyFname, tmpHeaderFname)
+		require.Nil(t, err)
+		require.Nil(t, store.Close())
+		if committed {
+			require.Nil(t, os.Rename(tmpBodyFname, store.bodyFname))
+		}
+
+		reopened, err := newFileStore(store.sessionID, fileStorePath)
+		require.Nil(t, err)
+		msgs, err := reopened.GetMessages(1, 6)
+		require.Nil(t, err)
+		if committed {
+			assert.Equal(t, [][]byte{[]byte("msg4"), []byte("msg5"), []byte("msg6")}, msgs)
+		} else {
+			assert.Len(t, msgs, 6)
+		}
+		assert.False(t, fileExists(tmpBodyFname))
+		assert.False(t, fileExists(tmpHeaderFname))
+		require.Nil(t, reopened.Close())
+	}
+}
+
+func TestFileStore_SyncPolicy(t *testing.T) {
+	store, _ := newFileStoreWithSettings(t, "")
+	assert.Equal(t, fileStoreSyncAlways, store.syncer.policy)
+
+	store, _ = newFileStoreWithSettings(t, "FileStoreSync=never")
+	assert.Equal(t, fileStoreSyncNever, store.syncer.policy)
+
+	store, _ = newFileStoreWithSettings(t, "FileStoreSync=BATCH")
+	assert.Equal(t, fileStoreSyncBatch, store.syncer.policy)
+	assert.NotNil(t, store.syncer.stop, "BATCH should default to a sync interval")
+
+	store, _ = newFileStoreWithSettings(t, "FileStoreSync=BATCH\nFileStoreSyncBatchSize=2")
+	assert.Equal(t, 2, store.syncer.batchSize)
+	assert.Nil(t, store.syncer.stop)
+
+	store, _ = newFileStoreWithSettings(t, "FileStoreSync=BATCH\nFileStoreSyncInterval=10ms")
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	assert.Eventually(t, func() bool {
+		store.syncer.mutex.Lock()
+		defer store.syncer.mutex.Unlock()
+		return len(store.syncer.dirty) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestFileStore_SyncPolicyInvalid(t *testing.T) {
+	fileStorePath := path.Join(os.TempDir(), fmt.Sprintf("FileStoreTest-%d-%d", os.Getpid(), time.Now().UnixNano()))
+	defer os.RemoveAll(fileStorePath)
+
+	settings, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+FileStorePath=` + fileStorePath + `
+FileStoreSync=SOMETIMES
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET`))
+	require.Nil(t, err)
+
+	_, err = NewFileStoreFactory(settings).Create(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+	assert.NotNil(t, err)
+}
+
+func TestFileStore_SyncBatchSize(t *testing.T) {
+	store, _ := newFileStoreWithSettings(t, "FileStoreSync=BATCH\nFileStoreSyncBatchSize=2")
+
+	require.Nil(t, store.SaveMessage(1, []byte("msg1")))
+	assert.Len(t, store.syncer.dirty, 2, "body and header should be pending")
+	assert.Equal(t, 1, store.syncer.pending)
+
+	require.Nil(t, store.SaveMessage(2, []byte("msg2")))
+	assert.Empty(t, store.syncer.dirty)
+	assert.Equal(t, 0, store.syncer.pending)
+
+	require.Nil(t, store.SaveMessage(3, []byte("msg3")))
+	require.Nil(t, store.Refresh())
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3")}, msgs)
+}
+
+func TestFileStore_SyncNever(t *testing.T) {
+	store, _ := newFileStoreWithSettings(t, "FileStoreSync=NEVER")
+
+	require.Nil(t, store.SaveMessage(1, []byte("msg1")))
+	require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	assert.Empty(t, store.syncer.dirty)
+
+	require.Nil(t, store.Refresh())
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+}
+
+func TestFileStore_RecoverTornRecord(t *testing.T) {
+	store, fileStorePath := newFileStoreWithSettings(t, "")
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.Close())
+
+	// simulate a crash while saving message 3: the body is torn and the header record is cut mid line
+	header, err := ioutil.ReadFile(store.headerFname)
+	require.Nil(t, err)
+	require.Nil(t, os.Truncate(store.headerFname, int64(len(header)-2)))
+	require.Nil(t, os.Truncate(store.bodyFname, 10))
+
+	recovered, err := newFileStore(store.sessionID, fileStorePath)
+	require.Nil(t, err)
+	defer recovered.Close()
+
+	msgs, err := recovered.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2")}, msgs)
+	assert.Equal(t, 4, recovered.NextSenderMsgSeqNum())
+
+	header, err = ioutil.ReadFile(recovered.headerFname)
+	require.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(header), "\n"), "torn header record should be truncated")
+
+	require.Nil(t, recovered.SaveMessage(4, []byte("msg4")))
+	require.Nil(t, recovered.Refresh())
+	msgs, err = recovered.GetMessages(1, 4)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg4")}, msgs)
+}
+
+func TestFileStore_RecoverLostSeqNum(t *testing.T) {
+	store, fileStorePath := newFileStoreWithSettings(t, "")
+
+	for seqNum := 1; seqNum <= 2; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.Close())
+
+	// simulate a crash before the seqnums write reached the disk
+	require.Nil(t, os.Truncate(store.senderSeqNumsFname, 0))
+
+	recovered, err := newFileStore(store.sessionID, fileStorePath)
+	require.Nil(t, err)
+	defer recovered.Close()
+
+	assert.Equal(t, 3, recovered.NextSenderMsgSeqNum())
+}
+
+func TestFileStore_LoweredSeqNumSurvivesRestart(t *testing.T) {
+	store, fileStorePath := newFileStoreWithSettings(t, "")
+
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.SetNextSenderMsgSeqNum(2))
+	require.Nil(t, store.Close())
+
+	restarted, err := newFileStore(store.sessionID, fileStorePath)
+	require.Nil(t, err)
+	defer restarted.Close()
+
+	assert.Equal(t, 2, restarted.NextSenderMsgSeqNum())
+}
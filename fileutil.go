@@ -3,6 +3,7 @@ package quickfix
 import (
 	"fmt"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -52,6 +53,28 @@ func removeFile(fname string) error {
 	return nil
 }
 
+// syncDir fsyncs a directory, so the renames of files in it are durable
+func syncDir(dirname string) error {
+	dir, err := os.Open(dirname)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		return errors.Wrapf(err, "sync %v", dirname)
+	}
+	return nil
+}
+
+// moveFile moves a file into dir, no error is returned if the file does not exist
+func moveFile(fname string, dir string) error {
+	if err := os.Rename(fname, path.Join(dir, path.Base(fname))); (err != nil) && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "move %v", fname)
+	}
+	return nil
+}
+
 // openOrCreateFile opens a file for reading and writing, creating it if necessary
 func openOrCreateFile(fname string, perm os.FileMode) (f *os.File, err error) {
 	if f, err = os.OpenFile(fname, os.O_RDWR, perm); err != nil {
@@ -0,0 +1,274 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+//maskedValue replaces the values of masked tags
+var maskedValue = []byte("***")
+
+type filteredLog struct {
+	Log
+	maskTags         map[Tag]bool
+	suppressMsgTypes map[string]bool
+}
+
+//filter returns the message to log, nil if the message is suppressed
+func (l filteredLog) filter(msg []byte) []byte {
+	if len(l.suppressMsgTypes) == 0 && len(l.maskTags) == 0 {
+		return msg
+	}
+
+	var filtered []byte
+	if len(l.maskTags) > 0 {
+		filtered = make([]byte, 0, len(msg))
+	}
+
+	for rest := msg; len(rest) > 0; {
+		field := rest
+		if end := bytes.IndexByte(rest, '\001'); end >= 0 {
+			field, rest = rest[:end+1], rest[end+1:]
+		} else {
+			rest = nil
+		}
+
+		sep := bytes.IndexByte(field, '=')
+		if sep < 0 {
+			filtered = append(filtered, field...)
+			continue
+		}
+
+		tag, err := strconv.Atoi(string(field[:sep]))
+		if err != nil {
+			filtered = append(filtered, field...)
+			continue
+		}
+
+		value := bytes.TrimSuffix(field[sep+1:], []byte{'\001'})
+		if Tag(tag) == tagMsgType && l.suppressMsgTypes[string(value)] {
+			return nil
+		}
+
+		if l.maskTags == nil {
+			continue
+		}
+
+		if l.maskTags[Tag(tag)] {
+			filtered = append(filtered, field[:sep+1]...)
+			filtered = append(filtered, maskedValue...)
+			filtered = append(filtered, field[sep+1+len(value):]...)
+		} else {
+			filtered = append(filtered, field...)
+		}
+	}
+
+	if filtered == nil {
+		return msg
+	}
+
+	return filtered
+}
+
+func (l filteredLog) OnIncoming(msg []byte) {
+	if filtered := l.filter(msg); filtered != nil {
+		l.Log.OnIncoming(filtered)
+	}
+}
+
+func (l filteredLog) OnOutgoing(msg []byte) {
+	if filtered := l.filter(msg); filtered != nil {
+		l.Log.OnOutgoing(filtered)
+	}
+}
+
+//maskEvent masks the values of masked tags in the tag=value pairs of event text, such as the fields of a message
+//quoted in it. A value ends at the next SOH, or also at white space when the pair does not follow a SOH.
+func (l filteredLog) maskEvent(text string) string {
+	if len(l.maskTags) == 0 {
+		return text
+	}
+
+	var masked strings.Builder
+	last := 0
+	for i := 0; i < len(text); i++ {
+		if !isDigit(text[i]) || (i > 0 && isWordChar(text[i-1])) {
+			continue
+		}
+
+		sep := i
+		for sep < len(text) && isDigit(text[sep]) {
+			sep++
+		}
+		if sep == len(text) || text[sep] != '=' {
+			i = sep
+			continue
+		}
+
+		afterSOH := i > 0 && text[i-1] == '\001'
+		end := sep + 1
+		for end < len(text) && text[end] != '\001' && (afterSOH || !isSpace(text[end])) {
+			end++
+		}
+
+		if tag, err := strconv.Atoi(text[i:sep]); err == nil && l.maskTags[Tag(tag)] {
+			masked.WriteString(text[last : sep+1])
+			masked.Write(maskedValue)
+			last = end
+		}
+		i = end - 1
+	}
+
+	if masked.Len() == 0 {
+		return text
+	}
+
+	masked.WriteString(text[last:])
+	return masked.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+//isWordChar is true for the characters that a tag cannot follow, e.g. the digits of "a1=b" are not a tag
+func isWordChar(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+func (l filteredLog) OnEvent(text string) {
+	l.Log.OnEvent(l.maskEvent(text))
+}
+
+func (l filteredLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+//OnLogEvent masks the message of event and its attributes that are strings or errors
+func (l filteredLog) OnLogEvent(event LogEvent) {
+	event.Message = l.maskEvent(event.Message)
+
+	if len(l.maskTags) > 0 && len(event.Attrs) > 0 {
+		attrs := make([]LogAttr, len(event.Attrs))
+		for i, attr := range event.Attrs {
+			switch v := attr.Value.(type) {
+			case string:
+				attr.Value = l.maskEvent(v)
+			case error:
+				if text, masked := v.Error(), l.maskEvent(v.Error()); masked != text {
+					attr.Value = masked
+				}
+			}
+			attrs[i] = attr
+		}
+		event.Attrs = attrs
+	}
+
+	logEvent(l.Log, event)
+}
+
+func (l filteredLog) Close() error {
+	if closer, ok := l.Log.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type filteredLogFactory struct {
+	factory  LogFactory
+	settings *Settings
+}
+
+//NewFilteredLogFactory wraps factory to mask the values of the tags in LogMaskTags and to suppress messages of the
+//MsgTypes in LogSuppressMsgTypes before they are logged. The values of the tags in LogMaskTags are also masked in the
+//tag=value pairs of events, such as a rejected Logon quoted in an event.
+func NewFilteredLogFactory(factory LogFactory, settings *Settings) (LogFactory, error) {
+	if _, err := newFilteredLog(nil, settings.GlobalSettings()); err != nil {
+		return nil, err
+	}
+
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, err := newFilteredLog(nil, sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return filteredLogFactory{factory: factory, settings: settings}, nil
+}
+
+func newFilteredLog(log Log, settings *SessionSettings) (filteredLog, error) {
+	l := filteredLog{Log: log}
+
+	if settings.HasSetting(config.LogMaskTags) {
+		tags, err := settings.Setting(config.LogMaskTags)
+		if err != nil {
+			return l, err
+		}
+
+		l.maskTags = make(map[Tag]bool)
+		for _, tagStr := range splitSettingList(tags) {
+			tag, err := strconv.Atoi(tagStr)
+			if err != nil {
+				return l, IncorrectFormatForSetting{Setting: config.LogMaskTags, Value: tags, Err: err}
+			}
+			l.maskTags[Tag(tag)] = true
+		}
+	}
+
+	if settings.HasSetting(config.LogSuppressMsgTypes) {
+		msgTypes, err := settings.Setting(config.LogSuppressMsgTypes)
+		if err != nil {
+			return l, err
+		}
+
+		l.suppressMsgTypes = make(map[string]bool)
+		for _, msgType := range splitSettingList(msgTypes) {
+			l.suppressMsgTypes[msgType] = true
+		}
+	}
+
+	return l, nil
+}
+
+//splitSettingList splits a comma separated setting, dropping empty values
+func splitSettingList(setting string) (values []string) {
+	for _, value := range strings.Split(setting, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return
+}
+
+func (f filteredLogFactory) Create() (Log, error) {
+	log, err := f.factory.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	return newFilteredLog(log, f.settings.GlobalSettings())
+}
+
+func (f filteredLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	log, err := f.factory.CreateSessionLog(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		closeLog(log)
+		return nil, errUnknownSession
+	}
+
+	return newFilteredLog(log, sessionSettings)
+}
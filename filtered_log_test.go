@@ -0,0 +1,131 @@
+package quickfix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFilteredLogTest(t *testing.T, cfg string) (Log, captureLogFactory) {
+	settings, err := ParseSettings(strings.NewReader(cfg))
+	require.Nil(t, err)
+
+	capture := newCaptureLogFactory()
+	factory, err := NewFilteredLogFactory(capture, settings)
+	require.Nil(t, err)
+
+	log, err := factory.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "S", TargetCompID: "T"})
+	require.Nil(t, err)
+
+	return log, capture
+}
+
+const filteredLogSettings = `
+[DEFAULT]
+LogMaskTags=554, 925,1
+LogSuppressMsgTypes=0,1
+
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=S
+TargetCompID=T
+`
+
+func TestFilteredLog_Mask(t *testing.T) {
+	log, capture := newFilteredLogTest(t, filteredLogSettings)
+
+	log.OnOutgoing([]byte("8=FIX.4.2\x019=50\x0135=A\x01554=secret\x01925=newsecret\x0110=000\x01"))
+	log.OnIncoming([]byte("8=FIX.4.2\x019=20\x0135=D\x011=ACCT\x0111=ID\x0110=000\x01"))
+
+	assert.Equal(t, []string{
+		"out:8=FIX.4.2\x019=50\x0135=A\x01554=***\x01925=***\x0110=000\x01",
+		"in:8=FIX.4.2\x019=20\x0135=D\x011=***\x0111=ID\x0110=000\x01",
+	}, *capture.entries)
+}
+
+func TestFilteredLog_Suppress(t *testing.T) {
+	log, capture := newFilteredLogTest(t, filteredLogSettings)
+
+	log.OnIncoming([]byte("8=FIX.4.2\x019=5\x0135=0\x0110=000\x01"))
+	log.OnOutgoing([]byte("8=FIX.4.2\x019=5\x0135=1\x01112=TEST\x0110=000\x01"))
+	log.OnEvent("event 112=TEST")
+
+	assert.Equal(t, []string{"event:event 112=TEST"}, *capture.entries, "events should not be suppressed")
+}
+
+func TestFilteredLog_MaskEvents(t *testing.T) {
+	log, capture := newFilteredLogTest(t, filteredLogSettings)
+
+	logon := NewMessage()
+	logon.Header.SetField(tagBeginString, FIXString("FIX.4.2"))
+	logon.Header.SetField(tagMsgType, FIXString("A"))
+	logon.Body.SetField(Tag(554), FIXString("my secret"))
+	logon.Body.SetField(tagHeartBtInt, FIXInt(30))
+	log.OnEventf("Invalid Session State: Received Msg %s while waiting for Logon", logon)
+	log.OnEvent(RejectLogon{Text: "Logon rejected, invalid 554=secret for 1=ACCT"}.Error())
+	log.OnEvent("Checksum 10=123 and 8=FIX.4.2 11=554=x")
+
+	assert.Equal(t, []string{
+		"event:Invalid Session State: Received Msg 8=FIX.4.2\x019=26\x0135=A\x01108=30\x01554=***\x0110=135\x01 while waiting for Logon",
+		"event:Logon rejected, invalid 554=*** for 1=***",
+		"event:Checksum 10=123 and 8=FIX.4.2 11=554=x",
+	}, *capture.entries)
+}
+
+func TestFilteredLog_MaskLogEvents(t *testing.T) {
+	var b bytes.Buffer
+	settings, err := ParseSettings(strings.NewReader(filteredLogSettings))
+	require.Nil(t, err)
+	factory, err := NewFilteredLogFactory(NewJSONLogFactory(&b), settings)
+	require.Nil(t, err)
+	log, err := factory.Create()
+	require.Nil(t, err)
+
+	logEvent(log, LogEvent{Level: LogLevelWarn, Code: LogCodeMessageRejected, Message: "Logon rejected: 554=secret",
+		Attrs: []LogAttr{{"error", RejectLogon{Text: "554=secret"}}, {"text", "1=ACCT"}, {"seq_num", 1}}})
+
+	assert.NotContains(t, b.String(), "secret")
+	assert.NotContains(t, b.String(), "ACCT")
+	assert.Contains(t, b.String(), `"seq_num":1`)
+}
+
+func TestFilteredLog_NoSettings(t *testing.T) {
+	log, capture := newFilteredLogTest(t, `
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=S
+TargetCompID=T
+`)
+
+	msg := "8=FIX.4.2\x019=5\x0135=0\x01554=secret\x0110=000\x01"
+	log.OnIncoming([]byte(msg))
+	assert.Equal(t, []string{"in:" + msg}, *capture.entries)
+}
+
+func TestFilteredLog_SessionSettings(t *testing.T) {
+	log, capture := newFilteredLogTest(t, `
+[DEFAULT]
+LogSuppressMsgTypes=0
+
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=S
+TargetCompID=T
+LogSuppressMsgTypes=1
+`)
+
+	log.OnIncoming([]byte("8=FIX.4.2\x0135=0\x01"))
+	log.OnIncoming([]byte("8=FIX.4.2\x0135=1\x01"))
+	assert.Equal(t, []string{"in:8=FIX.4.2\x0135=0\x01"}, *capture.entries)
+}
+
+func TestNewFilteredLogFactory_InvalidTags(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set("LogMaskTags", "554,Password")
+
+	_, err := NewFilteredLogFactory(NewNullLogFactory(), settings)
+	assert.NotNil(t, err)
+}
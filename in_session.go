@@ -2,6 +2,7 @@ package quickfix
 
 import (
 	"bytes"
+	"fmt"
 	"time"
 
 	"github.com/quickfixgo/quickfix/internal"
@@ -63,7 +64,8 @@ func (state inSession) Timeout(session *session, event internal.Event) (nextStat
 		if err := session.send(testReq); err != nil {
 			return handleStateError(session, err)
 		}
-		session.log.OnEvent("Sent test request TEST")
+		session.logEvent(LogLevelWarn, LogCodeTestRequestSent, "Sent test request TEST")
+		session.notify(HeartbeatTimeoutEvent{TestRequestSent: true})
 		session.peerTimer.Reset(time.Duration(float64(1.2) * float64(session.HeartBtInt)))
 		return pendingTimeout{state}
 	}
@@ -137,7 +139,8 @@ func (state inSession) handleSequenceReset(session *session, msg *Message) (next
 	var newSeqNo FIXInt
 	if err := msg.Body.GetField(tagNewSeqNo, &newSeqNo); err == nil {
 		expectedSeqNum := FIXInt(session.store.NextTargetMsgSeqNum())
-		session.log.OnEventf("Received SequenceReset FROM: %v TO: %v", expectedSeqNum, newSeqNo)
+		session.logEvent(LogLevelInfo, LogCodeSequenceResetReceived, fmt.Sprintf("Received SequenceReset FROM: %v TO: %v", expectedSeqNum, newSeqNo),
+			LogAttr{"begin_seq_no", expectedSeqNum}, LogAttr{"new_seq_no", newSeqNo})
 
 		switch {
 		case newSeqNo > expectedSeqNum:
@@ -174,7 +177,9 @@ func (state inSession) handleResendRequest(session *session, msg *Message) (next
 
 	endSeqNo := int(endSeqNoField)
 
-	session.log.OnEventf("Received ResendRequest FROM: %d TO: %d", beginSeqNo, endSeqNo)
+	session.logEvent(LogLevelInfo, LogCodeResendRequestReceived, fmt.Sprintf("Received ResendRequest FROM: %d TO: %d", beginSeqNo, endSeqNo),
+		LogAttr{"begin_seq_no", int(beginSeqNo)}, LogAttr{"end_seq_no", endSeqNo})
+	session.notify(ResendRequestReceivedEvent{BeginSeqNo: int(beginSeqNo), EndSeqNo: endSeqNo})
 	expectedSeqNum := session.store.NextSenderMsgSeqNum()
 
 	if (session.sessionID.BeginString >= BeginStringFIX42 && endSeqNo == 0) ||
@@ -207,42 +212,64 @@ func (state inSession) resendMessages(session *session, beginSeqNo, endSeqNo int
 		return
 	}
 
-	msgs, err := session.store.GetMessages(beginSeqNo, endSeqNo)
-	if err != nil {
-		session.log.OnEventf("error retrieving messages from store: %s", err.Error())
-		return
-	}
-
 	seqNum := beginSeqNo
 	nextSeqNum := seqNum
 	msg := NewMessage()
-	for _, msgBytes := range msgs {
+	resendMessage := func(msgBytes []byte) error {
 		_ = ParseMessageWithDataDictionary(msg, bytes.NewBuffer(msgBytes), session.transportDataDictionary, session.appDataDictionary)
 		msgType, _ := msg.Header.GetBytes(tagMsgType)
 		sentMessageSeqNum, _ := msg.Header.GetInt(tagMsgSeqNum)
 
 		if isAdminMessageType(msgType) {
 			nextSeqNum = sentMessageSeqNum + 1
-			continue
+			return nil
 		}
 
 		if !session.resend(msg) {
 			nextSeqNum = sentMessageSeqNum + 1
-			continue
+			return nil
 		}
 
 		if seqNum != sentMessageSeqNum {
-			if err = state.generateSequenceReset(session, seqNum, sentMessageSeqNum, inReplyTo); err != nil {
+			if err := state.generateSequenceReset(session, seqNum, sentMessageSeqNum, inReplyTo); err != nil {
 				return err
 			}
 		}
 
 		session.log.OnEventf("Resending Message: %v", sentMessageSeqNum)
-		msgBytes = msg.build()
-		session.EnqueueBytesAndSend(msgBytes)
+		session.EnqueueBytesAndSend(msg.build())
 
 		seqNum = sentMessageSeqNum + 1
 		nextSeqNum = seqNum
+		return nil
+	}
+
+	//stream the messages if the store supports it, so memory use does not grow with the size of the resend
+	if iterator, ok := session.store.(MessageIterator); ok {
+		var resendErr error
+		err = iterator.IterateMessages(beginSeqNo, endSeqNo, func(_ int, msgBytes []byte) error {
+			resendErr = resendMessage(msgBytes)
+			return resendErr
+		})
+		if resendErr != nil {
+			return resendErr
+		}
+		if err != nil {
+			session.log.OnEventf("error retrieving messages from store: %s", err.Error())
+			return
+		}
+	} else {
+		msgs, err := session.store.GetMessages(beginSeqNo, endSeqNo)
+		if err != nil {
+			session.log.OnEventf("error retrieving messages from store: %s", err.Error())
+			return err
+		}
+
+		for _, msgBytes := range msgs {
+			if err := resendMessage(msgBytes); err != nil {
+				return err
+			}
+		}
 	}
 
 	if seqNum != nextSeqNum { // gapfill for catch-up
@@ -383,7 +410,7 @@ func (state *inSession) generateSequenceReset(session *session, beginSeqNo int,
 	msgBytes := sequenceReset.build()
 
 	session.EnqueueBytesAndSend(msgBytes)
-	session.log.OnEventf("Sent SequenceReset TO: %v", endSeqNo)
+	session.logEvent(LogLevelInfo, LogCodeSequenceResetSent, fmt.Sprintf("Sent SequenceReset TO: %v", endSeqNo), LogAttr{"new_seq_no", endSeqNo})
 
 	return
 }
@@ -273,6 +273,73 @@ func (s *InSessionTestSuite) TestFIXMsgInResendRequestAllAdminThenApp() {
 	s.State(inSession{})
 }
 
+//getMessagesStore hides the MessageIterator of the wrapped store
+type getMessagesStore struct {
+	MessageStore
+}
+
+func (s *InSessionTestSuite) TestFIXMsgInResendRequestWithoutMessageIterator() {
+	s.session.store = getMessagesStore{&s.MockStore}
+
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.NeedHeartbeat)
+	s.LastToAdminMessageSent()
+
+	s.MockApp.On("ToApp").Return(nil)
+	s.Require().Nil(s.session.send(s.NewOrderSingle()))
+	s.LastToAppMessageSent()
+	s.NextSenderMsgSeqNum(3)
+
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.fixMsgIn(s.session, s.ResendRequest(1))
+
+	s.MockApp.AssertNumberOfCalls(s.T(), "ToAdmin", 2)
+	s.MockApp.AssertNumberOfCalls(s.T(), "ToApp", 2)
+
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeSequenceReset), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagNewSeqNo, 2, s.MockApp.lastToAdmin.Body)
+
+	s.LastToAppMessageSent()
+	s.MessageType("D", s.MockApp.lastToApp)
+	s.FieldEquals(tagMsgSeqNum, 2, s.MockApp.lastToApp.Header)
+	s.FieldEquals(tagPossDupFlag, true, s.MockApp.lastToApp.Header)
+
+	s.NextSenderMsgSeqNum(3)
+	s.State(inSession{})
+}
+
+func (s *InSessionTestSuite) TestFIXMsgInResendRequestBoundedStoreGapFill() {
+	store, err := NewBoundedMemoryStoreFactory(1, 0).Create(s.sessionID)
+	s.Require().Nil(err)
+	s.session.store = store
+
+	s.MockApp.On("ToApp").Return(nil)
+	s.Require().Nil(s.session.send(s.NewOrderSingle()))
+	s.LastToAppMessageSent()
+	s.Require().Nil(s.session.send(s.NewOrderSingle()))
+	s.LastToAppMessageSent()
+	s.NextSenderMsgSeqNum(3)
+
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("ToAdmin")
+	s.fixMsgIn(s.session, s.ResendRequest(1))
+
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeSequenceReset), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagMsgSeqNum, 1, s.MockApp.lastToAdmin.Header)
+	s.FieldEquals(tagNewSeqNo, 2, s.MockApp.lastToAdmin.Body)
+	s.FieldEquals(tagGapFillFlag, true, s.MockApp.lastToAdmin.Body)
+
+	s.LastToAppMessageSent()
+	s.MessageType("D", s.MockApp.lastToApp)
+	s.FieldEquals(tagMsgSeqNum, 2, s.MockApp.lastToApp.Header)
+	s.FieldEquals(tagPossDupFlag, true, s.MockApp.lastToApp.Header)
+
+	s.NextSenderMsgSeqNum(3)
+	s.State(inSession{})
+}
+
 func (s *InSessionTestSuite) TestFIXMsgInResendRequestNoMessagePersist() {
 	s.session.DisableMessagePersist = true
 
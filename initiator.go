@@ -2,7 +2,10 @@ package quickfix
 
 import (
 	"bufio"
+	"context"
 	"crypto/tls"
+	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
@@ -21,45 +24,257 @@ type Initiator struct {
 	stopChan        chan interface{}
 	wg              sync.WaitGroup
 	sessions        map[SessionID]*session
+	sessionsMutex   sync.RWMutex
+	reloadMutex     sync.Mutex
+	handlers        map[SessionID]*connectionHandler
+	started         bool
+	sessionAddr     sync.Map
+	sessionConns    sync.Map
 	sessionFactory
 }
 
+//connectionHandler tracks the handleConnection goroutine of a session
+type connectionHandler struct {
+	stop chan interface{}
+	done chan interface{}
+}
+
 //Start Initiator.
 func (i *Initiator) Start() (err error) {
+	return i.StartContext(context.Background())
+}
+
+//StartContext starts the Initiator. The context bounds starting only: if it is done before all sessions have started,
+//the sessions already started are stopped and the context error is returned. Use StopContext to stop.
+func (i *Initiator) StartContext(ctx context.Context) (err error) {
+	i.sessionsMutex.Lock()
+	defer i.sessionsMutex.Unlock()
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	i.stopChan = make(chan interface{})
+	i.handlers = make(map[SessionID]*connectionHandler)
 
-	for sessionID, settings := range i.sessionSettings {
-		//TODO: move into session factory
-		var tlsConfig *tls.Config
-		if tlsConfig, err = loadTLSConfig(settings); err != nil {
-			return
+	for sessionID := range i.sessionSettings {
+		if err = i.startSession(sessionID); err != nil {
+			break
 		}
 
-		var dialer proxy.Dialer
-		if dialer, err = loadDialerConfig(settings); err != nil {
-			return
+		if err = ctx.Err(); err != nil {
+			break
 		}
+	}
 
-		i.wg.Add(1)
-		go func(sessID SessionID) {
-			i.handleConnection(i.sessions[sessID], tlsConfig, dialer)
-			i.wg.Done()
-		}(sessionID)
+	if err != nil {
+		close(i.stopChan)
+		i.wg.Wait()
+		return
 	}
 
+	i.started = true
 	return
 }
 
 //Stop Initiator.
 func (i *Initiator) Stop() {
+	_ = i.StopContext(context.Background())
+}
+
+//StopContext stops connecting and logs out sessions. Messages queued for logged on sessions are sent ahead of the
+//Logout. Sessions wait for their Logout to be acknowledged until the ctx deadline, after which their connections are
+//forced closed. The sessions that could not be stopped gracefully are reported as SessionErrors.
+func (i *Initiator) StopContext(ctx context.Context) error {
+	if i.stopChan == nil {
+		return nil
+	}
+
 	select {
 	case <-i.stopChan:
 		//closed already
-		return
+		return nil
 	default:
 	}
+
+	i.sessionsMutex.Lock()
+	i.started = false
+	sessions := make([]*session, 0, len(i.sessions))
+	for _, session := range i.sessions {
+		sessions = append(sessions, session)
+	}
+	i.sessionsMutex.Unlock()
+
+	errs := stopSessions(ctx, sessions, &i.sessionConns)
+
 	close(i.stopChan)
 	i.wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//AddSession creates a session with the given settings, overlaid on the global settings of the Initiator. If the
+//Initiator is started, the session starts connecting. The settings are also added to the Settings the Initiator was
+//created with, so that store and log factories created with the same Settings know about the session.
+func (i *Initiator) AddSession(sessionID SessionID, sessionSettings *SessionSettings) (err error) {
+	i.sessionsMutex.Lock()
+	defer i.sessionsMutex.Unlock()
+
+	if _, dup := i.sessions[sessionID]; dup {
+		return errDuplicateSessionID
+	}
+
+	if err = i.settings.addSessionWithID(sessionID, sessionSettings); err != nil {
+		return
+	}
+
+	allSettings := i.settings.SessionSettings()[sessionID]
+	if _, err = loadTLSConfig(allSettings); err != nil {
+		i.settings.removeSession(sessionID)
+		return
+	}
+
+	if _, err = loadDialerConfig(allSettings); err != nil {
+		i.settings.removeSession(sessionID)
+		return
+	}
+
+	var s *session
+	if s, err = i.createSession(sessionID, i.storeFactory, allSettings, i.logFactory, i.app); err != nil {
+		i.settings.removeSession(sessionID)
+		return
+	}
+
+	i.sessionSettings[sessionID] = allSettings
+	i.sessions[sessionID] = s
+
+	if i.started {
+		if err = i.startSession(sessionID); err != nil {
+			//unwound so the session can be added again
+			delete(i.sessions, sessionID)
+			delete(i.sessionSettings, sessionID)
+			delete(i.handlers, sessionID)
+			i.settings.removeSession(sessionID)
+			_ = UnregisterSession(sessionID)
+			closeLog(s.log)
+			_ = s.store.Close()
+		}
+	}
+
+	return
+}
+
+//RemoveSession stops connecting the session, logs it out if it is logged on and closes its message store. Other
+//sessions are unaffected.
+func (i *Initiator) RemoveSession(sessionID SessionID) error {
+	i.sessionsMutex.Lock()
+	s, ok := i.sessions[sessionID]
+	if !ok {
+		i.sessionsMutex.Unlock()
+		return errUnknownSession
+	}
+	delete(i.sessions, sessionID)
+	delete(i.sessionSettings, sessionID)
+	i.settings.removeSession(sessionID)
+
+	handler := i.handlers[sessionID]
+	delete(i.handlers, sessionID)
+	i.sessionsMutex.Unlock()
+
+	if handler != nil {
+		close(handler.stop)
+		<-handler.done
+	}
+
+	if err := UnregisterSession(sessionID); err != nil {
+		return err
+	}
+
+	closeLog(s.log)
+	return s.store.Close()
+}
+
+//Reload applies updated settings, e.g. parsed from an edited configuration file. Sessions are compared with the
+//current settings: removed sessions are logged out and removed, added sessions are added. Changes to the schedule,
+//HeartBtInt, MaxLatency, validation flags and log and store paths are applied without interrupting the session;
+//a changed HeartBtInt takes effect from the next logon. Sessions with other changed settings are restarted.
+//Errors for individual sessions are reported as SessionErrors.
+func (i *Initiator) Reload(settings *Settings) (SettingsDiff, error) {
+	i.reloadMutex.Lock()
+	defer i.reloadMutex.Unlock()
+
+	return reloadSettings(i.settings, settings, i.RemoveSession, i.AddSession, i.reloadSession)
+}
+
+func (i *Initiator) reloadSession(sessionID SessionID, old, new *SessionSettings) error {
+	i.sessionsMutex.Lock()
+	defer i.sessionsMutex.Unlock()
+
+	s, ok := i.sessions[sessionID]
+	if !ok {
+		return errUnknownSession
+	}
+
+	i.sessionSettings[sessionID] = new
+	return i.sessionFactory.reloadSession(s, old, new, i.storeFactory, i.logFactory)
+}
+
+//startSession launches the connection handler of the session
+func (i *Initiator) startSession(sessionID SessionID) (err error) {
+	settings := i.sessionSettings[sessionID]
+
+	//TODO: move into session factory
+	var tlsConfig *tls.Config
+	if tlsConfig, err = loadTLSConfig(settings); err != nil {
+		return
+	}
+
+	var dialer proxy.Dialer
+	if dialer, err = loadDialerConfig(settings); err != nil {
+		return
+	}
+
+	handler := &connectionHandler{
+		stop: make(chan interface{}),
+		done: make(chan interface{}),
+	}
+	i.handlers[sessionID] = handler
+
+	session := i.sessions[sessionID]
+	session.markRunning()
+
+	i.wg.Add(1)
+	go func() {
+		i.handleConnection(session, tlsConfig, dialer, handler.stop)
+		close(handler.done)
+		i.wg.Done()
+	}()
+
+	return
+}
+
+//RemoteAddr returns the remote address of the connection for a given session.
+func (i *Initiator) RemoteAddr(sessionID SessionID) (net.Addr, bool) {
+	addr, ok := i.sessionAddr.Load(sessionID)
+	if !ok || addr == nil {
+		return nil, false
+	}
+	val, ok := addr.(net.Addr)
+	return val, ok
+}
+
+func (i *Initiator) sessionIDs() []SessionID {
+	i.sessionsMutex.RLock()
+	defer i.sessionsMutex.RUnlock()
+
+	sessionIDs := make([]SessionID, 0, len(i.sessions))
+	for _, session := range i.sessions {
+		sessionIDs = append(sessionIDs, session.sessionID)
+	}
+	return sessionIDs
 }
 
 //NewInitiator creates and initializes a new Initiator.
@@ -93,7 +308,7 @@ func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings
 }
 
 //waitForInSessionTime returns true if the session is in session, false if the handler should stop
-func (i *Initiator) waitForInSessionTime(session *session) bool {
+func (i *Initiator) waitForInSessionTime(session *session, stop <-chan interface{}) bool {
 	inSessionTime := make(chan interface{})
 	go func() {
 		session.waitForInSessionTime()
@@ -104,23 +319,29 @@ func (i *Initiator) waitForInSessionTime(session *session) bool {
 	case <-inSessionTime:
 	case <-i.stopChan:
 		return false
+	case <-stop:
+		return false
 	}
 
 	return true
 }
 
 //waitForReconnectInterval returns true if a reconnect should be re-attempted, false if handler should stop
-func (i *Initiator) waitForReconnectInterval(reconnectInterval time.Duration) bool {
+func (i *Initiator) waitForReconnectInterval(session *session, reconnectInterval time.Duration, stop <-chan interface{}) bool {
 	select {
 	case <-time.After(reconnectInterval):
+	case <-session.connectNow:
 	case <-i.stopChan:
 		return false
+	case <-stop:
+		return false
 	}
 
 	return true
 }
 
-func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, dialer proxy.Dialer) {
+//handleConnection connects the session until the Initiator is stopped or stop is closed
+func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, dialer proxy.Dialer, stop <-chan interface{}) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -129,74 +350,146 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 	}()
 
 	defer func() {
-		session.stop()
+		session.stopAndWait()
 		wg.Wait()
 	}()
 
-	connectionAttempt := 0
+	reconnect := newReconnectStrategy(session)
 
 	for {
-		if !i.waitForInSessionTime(session) {
+		if !i.waitForInSessionTime(session, stop) {
 			return
 		}
 
-		var disconnected chan interface{}
-		var msgIn chan fixIn
-		var msgOut chan []byte
-
-		address := session.SocketConnectAddress[connectionAttempt%len(session.SocketConnectAddress)]
-		session.log.OnEventf("Connecting to: %v", address)
+		address := reconnect.address()
+		session.logEvent(LogLevelInfo, LogCodeConnecting, fmt.Sprintf("Connecting to: %v", address), LogAttr{"address", address})
+		session.notify(ConnectingEvent{Address: address})
 
-		netConn, err := dialer.Dial("tcp", address)
+		disconnected, err := i.dial(session, tlsConfig, dialer, address)
 		if err != nil {
-			session.log.OnEventf("Failed to connect: %v", err)
-			goto reconnect
-		} else if tlsConfig != nil {
-			// Unless InsecureSkipVerify is true, server name config is required for TLS
-			// to verify the received certificate
-			if !tlsConfig.InsecureSkipVerify && len(tlsConfig.ServerName) == 0 {
-				serverName := address
-				if c := strings.LastIndex(serverName, ":"); c > 0 {
-					serverName = serverName[:c]
+			session.logEvent(LogLevelWarn, LogCodeConnectFailed, err.Error(), LogAttr{"address", address}, LogAttr{"error", err})
+
+			if !reconnect.failed() {
+				session.logEvent(LogLevelError, LogCodeReconnectFailed, fmt.Sprintf("Giving up reconnecting after %v attempts", reconnect.failures),
+					LogAttr{"attempts", reconnect.failures})
+				session.notify(ReconnectFailedEvent{Attempts: reconnect.failures})
+				if handler, ok := i.app.(ReconnectFailureHandler); ok {
+					handler.OnReconnectFailed(session.sessionID, reconnect.failures)
 				}
-				tlsConfig.ServerName = serverName
+				return
+			}
+		} else {
+			failback, ok := i.waitForDisconnect(session, dialer, reconnect, disconnected, stop)
+			if !ok {
+				return
 			}
-			tlsConn := tls.Client(netConn, tlsConfig)
-			if err = tlsConn.Handshake(); err != nil {
-				session.log.OnEventf("Failed handshake: %v", err)
-				goto reconnect
+
+			reconnect.disconnected()
+			if failback {
+				continue
 			}
-			netConn = tlsConn
 		}
 
-		msgIn = make(chan fixIn)
-		msgOut = make(chan []byte)
-		if err := session.connect(msgIn, msgOut); err != nil {
-			session.log.OnEventf("Failed to initiate: %v", err)
-			goto reconnect
+		interval := reconnect.wait()
+		session.logEvent(LogLevelInfo, LogCodeReconnecting, fmt.Sprintf("Reconnecting in %v", interval), LogAttr{"interval", interval})
+		session.notify(ReconnectingEvent{Interval: interval})
+		if !i.waitForReconnectInterval(session, interval, stop) {
+			return
 		}
+	}
+}
+
+//dial connects the session to address, returning a channel that is closed once the connection is closed
+func (i *Initiator) dial(session *session, tlsConfig *tls.Config, dialer proxy.Dialer, address string) (disconnected chan interface{}, err error) {
+	netConn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		session.notify(ConnectFailedEvent{Address: address, Err: err})
+		return nil, fmt.Errorf("Failed to connect: %v", err)
+	}
 
-		go readLoop(newParser(bufio.NewReader(netConn)), msgIn)
-		disconnected = make(chan interface{})
-		go func() {
-			writeLoop(netConn, msgOut, session.log)
-			if err := netConn.Close(); err != nil {
-				session.log.OnEvent(err.Error())
+	if tlsConfig != nil {
+		// Unless InsecureSkipVerify is true, server name config is required for TLS
+		// to verify the received certificate
+		if !tlsConfig.InsecureSkipVerify && len(tlsConfig.ServerName) == 0 {
+			serverName := address
+			if c := strings.LastIndex(serverName, ":"); c > 0 {
+				serverName = serverName[:c]
 			}
-			close(disconnected)
-		}()
+			tlsConfig.ServerName = serverName
+		}
+		tlsConn := tls.Client(netConn, tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			netConn.Close()
+			session.notify(TLSHandshakeFailedEvent{Address: address, Err: err})
+			return nil, fmt.Errorf("Failed handshake: %v", err)
+		}
+		netConn = tlsConn
+	}
+
+	msgIn := make(chan fixIn)
+	msgOut := make(chan fixOut)
+	if err = session.connect(msgIn, msgOut); err != nil {
+		netConn.Close()
+		session.notify(ConnectFailedEvent{Address: address, Err: err})
+		return nil, fmt.Errorf("Failed to initiate: %v", err)
+	}
+
+	session.notify(ConnectedEvent{RemoteAddr: netConn.RemoteAddr()})
+
+	i.sessionAddr.Store(session.sessionID, netConn.RemoteAddr())
+	i.sessionConns.Store(session, netConn)
+	go readLoop(newParser(bufio.NewReader(netConn)), msgIn)
+	disconnected = make(chan interface{})
+	go func() {
+		writeLoop(netConn, msgOut, session.log)
+		if err := netConn.Close(); err != nil {
+			session.log.OnEvent(err.Error())
+		}
+		i.sessionAddr.Delete(session.sessionID)
+		i.sessionConns.Delete(session)
+		close(disconnected)
+	}()
+
+	return disconnected, nil
+}
+
+//waitForDisconnect returns true once the session is disconnected, false if the handler should stop. While connected
+//to a backup address with SocketConnectFailback, the primary address is probed and, once reachable, the session is
+//logged out to fail back to it.
+func (i *Initiator) waitForDisconnect(session *session, dialer proxy.Dialer, reconnect *reconnectStrategy,
+	disconnected <-chan interface{}, stop <-chan interface{}) (failback bool, ok bool) {
 
+	var primaryReachable <-chan struct{}
+	if reconnect.onBackup() {
+		probeStop := make(chan struct{})
+		defer close(probeStop)
+		primaryReachable = probeAddress(dialer, reconnect.primary(), session.ReconnectInterval, probeStop)
+	}
+
+	for {
 		select {
 		case <-disconnected:
+			return failback, true
+
+		case <-primaryReachable:
+			primaryReachable = nil
+			failback = true
+			session.logEvent(LogLevelInfo, LogCodeFailback, fmt.Sprintf("Primary address %v reachable, failing back", reconnect.primary()),
+				LogAttr{"address", reconnect.primary()})
+
+			rep := make(chan error, 1)
+			if err := session.sendAdmin(logoutReq{rep: rep}); err != nil || <-rep != nil {
+				rep = make(chan error, 1)
+				if err := session.sendAdmin(disconnectReq{rep}); err == nil {
+					<-rep
+				}
+			}
+
 		case <-i.stopChan:
-			return
-		}
+			return false, false
 
-	reconnect:
-		connectionAttempt++
-		session.log.OnEventf("Reconnecting in %v", session.ReconnectInterval)
-		if !i.waitForReconnectInterval(session.ReconnectInterval) {
-			return
+		case <-stop:
+			return false, false
 		}
 	}
 }
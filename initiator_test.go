@@ -0,0 +1,65 @@
+package quickfix
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitiator_AddRemoveSession(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+	settings.GlobalSettings().Set(config.SocketConnectHost, "127.0.0.1")
+	settings.GlobalSettings().Set(config.SocketConnectPort, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	settings.GlobalSettings().Set(config.ReconnectInterval, "1")
+
+	app := &MockApp{}
+	app.On("ToAdmin").Maybe()
+	app.On("OnLogout").Maybe()
+
+	initiator, err := NewInitiator(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.Nil(t, err)
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	sessionID := SessionID{BeginString: BeginStringFIX42, SenderCompID: "initiator", TargetCompID: "added"}
+	require.Nil(t, initiator.AddSession(sessionID, nil))
+	assert.Equal(t, errDuplicateSessionID, initiator.AddSession(sessionID, nil))
+	assert.Contains(t, settings.SessionSettings(), sessionID)
+
+	session, ok := LookupSession(sessionID)
+	require.True(t, ok)
+	_, err = session.Status()
+	assert.Nil(t, err, "added session should be running")
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("added session should connect")
+	}
+
+	assert.Nil(t, initiator.RemoveSession(sessionID))
+	assert.NotContains(t, settings.SessionSettings(), sessionID)
+	_, ok = LookupSession(sessionID)
+	assert.False(t, ok)
+	_, err = session.Status()
+	assert.Equal(t, errSessionNotRunning, err)
+	assert.Equal(t, errUnknownSession, initiator.RemoveSession(sessionID))
+}
@@ -22,8 +22,12 @@ type SessionSettings struct {
 	DefaultApplVerID string
 
 	//specific to initiators
-	ReconnectInterval    time.Duration
-	LogoutTimeout        time.Duration
-	LogonTimeout         time.Duration
-	SocketConnectAddress []string
+	ReconnectInterval     time.Duration
+	ReconnectIntervalMax  time.Duration
+	ReconnectJitter       int
+	MaxReconnectAttempts  int
+	SocketConnectFailback bool
+	LogoutTimeout         time.Duration
+	LogonTimeout          time.Duration
+	SocketConnectAddress  []string
 }
@@ -0,0 +1,105 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+//jsonLogLine is a line written by jsonLog
+type jsonLogLine struct {
+	Time    string                 `json:"time"`
+	Level   string                 `json:"level"`
+	Code    string                 `json:"code"`
+	Session string                 `json:"session,omitempty"`
+	Message string                 `json:"message"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+}
+
+type jsonLog struct {
+	sessionID SessionID
+	writer    io.Writer
+	mutex     *sync.Mutex
+}
+
+func (l jsonLog) write(event LogEvent) {
+	line := jsonLogLine{
+		Time:    event.Time.UTC().Format(time.RFC3339Nano),
+		Level:   event.Level.String(),
+		Code:    event.Code,
+		Message: event.Message,
+	}
+
+	if event.SessionID != (SessionID{}) {
+		line.Session = event.SessionID.String()
+	}
+
+	if len(event.Attrs) > 0 {
+		line.Attrs = make(map[string]interface{}, len(event.Attrs))
+		for _, attr := range event.Attrs {
+			line.Attrs[attr.Key] = jsonLogValue(attr.Value)
+		}
+	}
+
+	bytes, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, _ = l.writer.Write(append(bytes, '\n'))
+}
+
+//jsonLogValue converts errors and other values with a string form, such as addresses and durations, to strings
+func jsonLogValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return value
+}
+
+func (l jsonLog) OnIncoming(msg []byte) {
+	l.write(LogEvent{Time: time.Now(), Level: LogLevelInfo, Code: LogCodeIncoming, SessionID: l.sessionID, Message: string(msg)})
+}
+
+func (l jsonLog) OnOutgoing(msg []byte) {
+	l.write(LogEvent{Time: time.Now(), Level: LogLevelInfo, Code: LogCodeOutgoing, SessionID: l.sessionID, Message: string(msg)})
+}
+
+func (l jsonLog) OnEvent(msg string) {
+	l.write(LogEvent{Time: time.Now(), Level: LogLevelInfo, Code: LogCodeEvent, SessionID: l.sessionID, Message: msg})
+}
+
+func (l jsonLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+func (l jsonLog) OnLogEvent(event LogEvent) {
+	l.write(event)
+}
+
+type jsonLogFactory struct {
+	writer io.Writer
+	mutex  *sync.Mutex
+}
+
+//NewJSONLogFactory creates an instance of LogFactory that writes messages and events to w as JSON lines, one object
+//per line with time, level, code, session, message and attrs fields. The logs of all sessions share w.
+func NewJSONLogFactory(w io.Writer) LogFactory {
+	return jsonLogFactory{writer: w, mutex: new(sync.Mutex)}
+}
+
+func (f jsonLogFactory) Create() (Log, error) {
+	return jsonLog{writer: f.writer, mutex: f.mutex}, nil
+}
+
+func (f jsonLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return jsonLog{sessionID: sessionID, writer: f.writer, mutex: f.mutex}, nil
+}
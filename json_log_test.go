@@ -0,0 +1,105 @@
+package quickfix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readJSONLines(t *testing.T, buf *bytes.Buffer) (lines []map[string]interface{}) {
+	for _, text := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var line map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(text), &line), text)
+		lines = append(lines, line)
+	}
+	return
+}
+
+func TestJSONLog(t *testing.T) {
+	var buf bytes.Buffer
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "S", TargetCompID: "T"}
+	log, err := NewJSONLogFactory(&buf).CreateSessionLog(sessionID)
+	require.Nil(t, err)
+
+	log.OnIncoming([]byte("8=FIX.4.2\x01"))
+	log.OnEventf("event %d", 1)
+	logEvent(log, LogEvent{
+		Level:     LogLevelWarn,
+		Code:      LogCodeConnectFailed,
+		SessionID: sessionID,
+		Message:   "Failed to connect",
+		Attrs: []LogAttr{
+			{"address", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5001}},
+			{"error", errors.New("refused")},
+			{"interval", 2 * time.Second},
+			{"attempts", 3},
+		},
+	})
+
+	lines := readJSONLines(t, &buf)
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, "info", lines[0]["level"])
+	assert.Equal(t, LogCodeIncoming, lines[0]["code"])
+	assert.Equal(t, "FIX.4.2:S->T", lines[0]["session"])
+	assert.Equal(t, "8=FIX.4.2\x01", lines[0]["message"])
+
+	assert.Equal(t, LogCodeEvent, lines[1]["code"])
+	assert.Equal(t, "event 1", lines[1]["message"])
+
+	assert.Equal(t, "warn", lines[2]["level"])
+	assert.Equal(t, LogCodeConnectFailed, lines[2]["code"])
+	assert.Equal(t, map[string]interface{}{
+		"address":  "127.0.0.1:5001",
+		"error":    "refused",
+		"interval": "2s",
+		"attempts": float64(3),
+	}, lines[2]["attrs"])
+	_, err = time.Parse(time.RFC3339Nano, lines[2]["time"].(string))
+	assert.Nil(t, err)
+}
+
+func TestJSONLog_Global(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewJSONLogFactory(&buf).Create()
+	require.Nil(t, err)
+
+	log.OnEvent("global")
+
+	lines := readJSONLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.NotContains(t, lines[0], "session")
+	assert.NotContains(t, lines[0], "attrs")
+}
+
+func TestLogEvent_PlainLog(t *testing.T) {
+	factory := newCaptureLogFactory()
+	log, _ := factory.Create()
+
+	logEvent(log, LogEvent{Level: LogLevelError, Code: LogCodeError, Message: "failed"})
+	assert.Equal(t, []string{"event:failed"}, *factory.entries, "logs that are not structured should receive the message")
+}
+
+func TestSessionLogEvent(t *testing.T) {
+	var buf bytes.Buffer
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "S", TargetCompID: "T"}
+	log, _ := NewJSONLogFactory(&buf).CreateSessionLog(sessionID)
+	composite := newCompositeLog([]Log{log})
+	s := &session{sessionID: sessionID, log: newSwappableLog(composite)}
+
+	s.logError(errors.New("store failed"))
+	require.Nil(t, composite.Close())
+
+	lines := readJSONLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Equal(t, LogCodeError, lines[0]["code"])
+	assert.Equal(t, map[string]interface{}{"error": "store failed"}, lines[0]["attrs"])
+}
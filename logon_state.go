@@ -47,7 +47,7 @@ func (s logonState) FixMsgIn(session *session, msg *Message) (nextState sessionS
 func (s logonState) Timeout(session *session, e internal.Event) (nextState sessionState) {
 	switch e {
 	case internal.LogonTimeout:
-		session.log.OnEvent("Timed out waiting for logon response")
+		session.logEvent(LogLevelError, LogCodeLogonTimeout, "Timed out waiting for logon response")
 		return latentState{}
 	}
 	return s
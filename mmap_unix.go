@@ -0,0 +1,41 @@
+//go:build !windows
+// +build !windows
+
+package quickfix
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the file read only, an empty file is returned as nil
+func mmapFile(fname string) ([]byte, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %s: %s", fname, err.Error())
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, nil
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("unable to map file: %s: %s", fname, err.Error())
+	}
+	return data, nil
+}
+
+// munmapFile unmaps data returned by mmapFile
+func munmapFile(data []byte) error {
+	if data == nil {
+		return nil
+	}
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,13 @@
+package quickfix
+
+import "io/ioutil"
+
+// mmapFile reads the file, sealed segments are not memory mapped on windows
+func mmapFile(fname string) ([]byte, error) {
+	return ioutil.ReadFile(fname)
+}
+
+// munmapFile releases data returned by mmapFile
+func munmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,745 @@
+package quickfix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// defaultMmapStoreSegmentSize is the size in bytes at which a segment is sealed if MmapStoreSegmentSize is not set
+const defaultMmapStoreSegmentSize = 64 << 20
+
+// mmapIndexEntrySize is the size of an index record: seqnum uint64, offset uint32, size uint32
+const mmapIndexEntrySize = 16
+
+type mmapStoreFactory struct {
+	settings *Settings
+}
+
+// mmapSegment is a body file of appended messages and its index of fixed size records. Sealed segments are memory
+// mapped when first read from, the active segment keeps its index in memory and is appended to. The seqnum range of
+// a sealed segment is kept in its range file, so that the segments holding a range are found without mapping them.
+type mmapSegment struct {
+	number     int
+	bodyFname  string
+	indexFname string
+	rangeFname string
+
+	body  []byte
+	index []byte
+
+	// set once the index is loaded
+	loaded bool
+
+	// set once the index is loaded or the range file is read
+	ranged    bool
+	count     int
+	minSeqNum int
+	maxSeqNum int
+	sorted    bool
+
+	// only for the active segment
+	bodyFile  *os.File
+	indexFile *os.File
+	bodySize  int64
+
+	// only for sealed segments
+	mapped bool
+}
+
+type mmapStore struct {
+	sessionID          SessionID
+	cache              *memoryStore
+	dirname            string
+	prefix             string
+	segmentSize        int64
+	segments           []*mmapSegment
+	sessionFname       string
+	senderSeqNumsFname string
+	targetSeqNumsFname string
+	sessionFile        *os.File
+	senderSeqNumsFile  *os.File
+	targetSeqNumsFile  *os.File
+	syncer             *fileStoreSyncer
+}
+
+// NewMmapStoreFactory returns a MessageStoreFactory of stores that append messages to segment files with a compact
+// index, memory mapping sealed segments for reading. Only the active segment is read when the store is opened.
+func NewMmapStoreFactory(settings *Settings) MessageStoreFactory {
+	return mmapStoreFactory{settings: settings}
+}
+
+// Create creates a new mmap store implementation of the MessageStore interface
+func (f mmapStoreFactory) Create(sessionID SessionID) (msgStore MessageStore, err error) {
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %v", sessionID)
+	}
+	dirname, err := sessionSettings.Setting(config.MmapStorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentSize := int64(defaultMmapStoreSegmentSize)
+	if sessionSettings.HasSetting(config.MmapStoreSegmentSize) {
+		size, err := sessionSettings.IntSetting(config.MmapStoreSegmentSize)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 || int64(size) > math.MaxUint32 {
+			return nil, errors.New("MmapStoreSegmentSize must be greater than 0 and less than 4GiB")
+		}
+		segmentSize = int64(size)
+	}
+
+	syncer, err := newFileStoreSyncer(sessionSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newMmapStore(sessionID, dirname, segmentSize, syncer)
+	if err != nil {
+		syncer.close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func newMmapStore(sessionID SessionID, dirname string, segmentSize int64, syncer *fileStoreSyncer) (*mmapStore, error) {
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	prefix := sessionIDFilenamePrefix(sessionID)
+	store := &mmapStore{
+		sessionID:          sessionID,
+		cache:              &memoryStore{},
+		dirname:            dirname,
+		prefix:             prefix,
+		segmentSize:        segmentSize,
+		sessionFname:       path.Join(dirname, fmt.Sprintf("%s.%s", prefix, "session")),
+		senderSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", prefix, "senderseqnums")),
+		targetSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", prefix, "targetseqnums")),
+		syncer:             syncer,
+	}
+
+	if err := store.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (store *mmapStore) newSegment(number int) *mmapSegment {
+	base := path.Join(store.dirname, fmt.Sprintf("%s.%08d", store.prefix, number))
+	return &mmapSegment{number: number, bodyFname: base + ".body", indexFname: base + ".index", rangeFname: base + ".range"}
+}
+
+// listSegments returns the segments in the store directory by number, without reading them
+func (store *mmapStore) listSegments() ([]*mmapSegment, error) {
+	fnames, err := filepath.Glob(path.Join(store.dirname, store.prefix+".*.body"))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*mmapSegment
+	for _, fname := range fnames {
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path.Base(fname), store.prefix+"."), ".body"))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, store.newSegment(number))
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].number < segments[j].number })
+	return segments, nil
+}
+
+// Reset deletes the store files and sets the seqnums back to 1
+func (store *mmapStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	if err := store.closeFiles(); err != nil {
+		return errors.Wrap(err, "close")
+	}
+
+	segments, err := store.listSegments()
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if err := removeFile(segment.bodyFname); err != nil {
+			return err
+		}
+		if err := removeFile(segment.indexFname); err != nil {
+			return err
+		}
+		if err := removeFile(segment.rangeFname); err != nil {
+			return err
+		}
+	}
+
+	if err := removeFile(store.sessionFname); err != nil {
+		return err
+	}
+	if err := removeFile(store.senderSeqNumsFname); err != nil {
+		return err
+	}
+	if err := removeFile(store.targetSeqNumsFname); err != nil {
+		return err
+	}
+	return store.Refresh()
+}
+
+// Refresh closes the store files and then reloads from them. Only the index of the active segment is read.
+func (store *mmapStore) Refresh() (err error) {
+	if err = store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	if err = store.closeFiles(); err != nil {
+		return err
+	}
+
+	if store.segments, err = store.listSegments(); err != nil {
+		return err
+	}
+	if len(store.segments) == 0 {
+		store.segments = append(store.segments, store.newSegment(1))
+	}
+	if err = store.active().open(); err != nil {
+		return err
+	}
+
+	creationTimePopulated, senderSeqNumRead := store.populateCache()
+
+	if store.sessionFile, err = openOrCreateFile(store.sessionFname, 0660); err != nil {
+		return err
+	}
+	if store.senderSeqNumsFile, err = openOrCreateFile(store.senderSeqNumsFname, 0660); err != nil {
+		return err
+	}
+	if store.targetSeqNumsFile, err = openOrCreateFile(store.targetSeqNumsFname, 0660); err != nil {
+		return err
+	}
+
+	if !creationTimePopulated {
+		if err := store.setSession(); err != nil {
+			return err
+		}
+	}
+
+	// a torn or missing seqnum file must not send a MsgSeqNum that was already used by a stored message. A seqnum
+	// file that was read whole is kept, it may have been set below the stored messages on purpose.
+	if !senderSeqNumRead {
+		if maxSeqNum, err := store.maxSeqNum(); err != nil {
+			return err
+		} else if maxSeqNum >= store.cache.NextSenderMsgSeqNum() {
+			if err := store.cache.SetNextSenderMsgSeqNum(maxSeqNum + 1); err != nil {
+				return errors.Wrap(err, "cache set next sender")
+			}
+		}
+	}
+
+	if err := store.SetNextSenderMsgSeqNum(store.NextSenderMsgSeqNum()); err != nil {
+		return errors.Wrap(err, "set next sender")
+	}
+	if err := store.SetNextTargetMsgSeqNum(store.NextTargetMsgSeqNum()); err != nil {
+		return errors.Wrap(err, "set next target")
+	}
+	return nil
+}
+
+// populateCache reads the session and seqnum files, senderSeqNumRead is false if the sender seqnum file is torn or
+// missing
+func (store *mmapStore) populateCache() (creationTimePopulated, senderSeqNumRead bool) {
+	if timeBytes, err := ioutil.ReadFile(store.sessionFname); err == nil {
+		var ctime time.Time
+		if err := ctime.UnmarshalText(timeBytes); err == nil {
+			store.cache.creationTime = ctime
+			creationTimePopulated = true
+		}
+	}
+
+	if senderSeqNumBytes, err := ioutil.ReadFile(store.senderSeqNumsFname); err == nil {
+		if senderSeqNum, err := strconv.Atoi(string(senderSeqNumBytes)); err == nil {
+			_ = store.cache.SetNextSenderMsgSeqNum(senderSeqNum)
+			senderSeqNumRead = len(senderSeqNumBytes) == seqNumWidth
+		}
+	}
+
+	if targetSeqNumBytes, err := ioutil.ReadFile(store.targetSeqNumsFname); err == nil {
+		if targetSeqNum, err := strconv.Atoi(string(targetSeqNumBytes)); err == nil {
+			_ = store.cache.SetNextTargetMsgSeqNum(targetSeqNum)
+		}
+	}
+
+	return creationTimePopulated, senderSeqNumRead
+}
+
+// maxSeqNum returns the highest seqnum in the newest non empty segment
+func (store *mmapStore) maxSeqNum() (int, error) {
+	for i := len(store.segments) - 1; i >= 0; i-- {
+		segment := store.segments[i]
+		if err := segment.loadRange(); err != nil {
+			return 0, err
+		}
+		if segment.count > 0 {
+			return segment.maxSeqNum, nil
+		}
+	}
+	return 0, nil
+}
+
+func (store *mmapStore) active() *mmapSegment {
+	return store.segments[len(store.segments)-1]
+}
+
+func (store *mmapStore) setSession() error {
+	if _, err := store.sessionFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind file: %s: %s", store.sessionFname, err.Error())
+	}
+
+	data, err := store.cache.CreationTime().MarshalText()
+	if err != nil {
+		return fmt.Errorf("unable to marshal session time to file: %s: %s", store.sessionFname, err.Error())
+	}
+	if _, err := store.sessionFile.Write(data); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", store.sessionFname, err.Error())
+	}
+	if err := store.sessionFile.Sync(); err != nil {
+		return fmt.Errorf("unable to flush file: %s: %s", store.sessionFname, err.Error())
+	}
+	return nil
+}
+
+func (store *mmapStore) setSeqNum(f *os.File, seqNum int) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind file: %s: %s", f.Name(), err.Error())
+	}
+	if _, err := fmt.Fprintf(f, "%0*d", seqNumWidth, seqNum); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", f.Name(), err.Error())
+	}
+	return store.syncer.written(f)
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent
+func (store *mmapStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received
+func (store *mmapStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent
+func (store *mmapStore) SetNextSenderMsgSeqNum(next int) error {
+	if err := store.cache.SetNextSenderMsgSeqNum(next); err != nil {
+		return errors.Wrap(err, "cache")
+	}
+	return store.setSeqNum(store.senderSeqNumsFile, next)
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received
+func (store *mmapStore) SetNextTargetMsgSeqNum(next int) error {
+	if err := store.cache.SetNextTargetMsgSeqNum(next); err != nil {
+		return errors.Wrap(err, "cache")
+	}
+	return store.setSeqNum(store.targetSeqNumsFile, next)
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent
+func (store *mmapStore) IncrNextSenderMsgSeqNum() error {
+	if err := store.cache.IncrNextSenderMsgSeqNum(); err != nil {
+		return errors.Wrap(err, "cache")
+	}
+	return store.setSeqNum(store.senderSeqNumsFile, store.cache.NextSenderMsgSeqNum())
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received
+func (store *mmapStore) IncrNextTargetMsgSeqNum() error {
+	if err := store.cache.IncrNextTargetMsgSeqNum(); err != nil {
+		return errors.Wrap(err, "cache")
+	}
+	return store.setSeqNum(store.targetSeqNumsFile, store.cache.NextTargetMsgSeqNum())
+}
+
+// CreationTime returns the creation time of the store
+func (store *mmapStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime sets the creation time of the store
+func (store *mmapStore) SetCreationTime(t time.Time) error {
+	if err := store.sessionFile.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate file: %s: %s", store.sessionFname, err.Error())
+	}
+	store.cache.creationTime = t
+	return store.setSession()
+}
+
+// SaveMessage appends msg to the active segment, sealing it first if msg would not fit
+func (store *mmapStore) SaveMessage(seqNum int, msg []byte) error {
+	active := store.active()
+	if active.bodySize > 0 && active.bodySize+int64(len(msg)) > store.segmentSize {
+		if err := store.seal(); err != nil {
+			return err
+		}
+		active = store.active()
+	}
+
+	if active.bodySize+int64(len(msg)) > math.MaxUint32 {
+		return fmt.Errorf("message of %d bytes exceeds the maximum segment size", len(msg))
+	}
+
+	// the body is written ahead of the index, so an index record never refers to a body that was not written
+	if _, err := active.bodyFile.WriteAt(msg, active.bodySize); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", active.bodyFname, err.Error())
+	}
+
+	var entry [mmapIndexEntrySize]byte
+	binary.BigEndian.PutUint64(entry[0:8], uint64(seqNum))
+	binary.BigEndian.PutUint32(entry[8:12], uint32(active.bodySize))
+	binary.BigEndian.PutUint32(entry[12:16], uint32(len(msg)))
+	if _, err := active.indexFile.WriteAt(entry[:], int64(len(active.index))); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", active.indexFname, err.Error())
+	}
+
+	active.append(entry[:])
+	active.bodySize += int64(len(msg))
+
+	return store.syncer.written(active.bodyFile, active.indexFile)
+}
+
+// seal closes the active segment and starts a new one
+func (store *mmapStore) seal() error {
+	if err := store.syncer.flush(); err != nil {
+		return err
+	}
+
+	active := store.active()
+	if err := active.writeRange(); err != nil {
+		return err
+	}
+	if err := active.close(); err != nil {
+		return err
+	}
+
+	segment := store.newSegment(active.number + 1)
+	if err := segment.open(); err != nil {
+		return err
+	}
+
+	store.segments = append(store.segments, segment)
+	return nil
+}
+
+type mmapStoreMessage struct {
+	seqNum int
+	msg    []byte
+}
+
+// GetMessages returns the messages in the range of seqnums, searching the index of each segment that may hold them.
+// Only the sealed segments whose seqnum range overlaps the range are mapped.
+func (store *mmapStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var found []mmapStoreMessage
+	ordered := true
+
+	for _, segment := range store.segments {
+		if err := segment.loadRange(); err != nil {
+			return nil, err
+		}
+		if segment.count == 0 || segment.maxSeqNum < beginSeqNum || segment.minSeqNum > endSeqNum {
+			continue
+		}
+		if err := segment.mmap(); err != nil {
+			return nil, err
+		}
+
+		n := segment.len()
+		i := 0
+		if segment.sorted {
+			i = sort.Search(n, func(i int) bool { return segment.seqNum(i) >= beginSeqNum })
+		}
+
+		for ; i < n; i++ {
+			seqNum := segment.seqNum(i)
+			if seqNum > endSeqNum && segment.sorted {
+				break
+			}
+			if seqNum < beginSeqNum || seqNum > endSeqNum {
+				continue
+			}
+
+			msg, err := segment.read(i)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(found) > 0 && seqNum <= found[len(found)-1].seqNum {
+				ordered = false
+			}
+			found = append(found, mmapStoreMessage{seqNum: seqNum, msg: msg})
+		}
+	}
+
+	if !ordered {
+		// a seqnum saved again replaces the message saved before
+		sort.SliceStable(found, func(i, j int) bool { return found[i].seqNum < found[j].seqNum })
+		deduped := found[:0]
+		for _, m := range found {
+			if len(deduped) > 0 && deduped[len(deduped)-1].seqNum == m.seqNum {
+				deduped[len(deduped)-1] = m
+				continue
+			}
+			deduped = append(deduped, m)
+		}
+		found = deduped
+	}
+
+	var msgs [][]byte
+	for _, m := range found {
+		msgs = append(msgs, m.msg)
+	}
+	return msgs, nil
+}
+
+// Close syncs pending writes and closes the store's files
+func (store *mmapStore) Close() error {
+	if err := store.syncer.close(); err != nil {
+		return err
+	}
+	return store.closeFiles()
+}
+
+// closeFiles syncs pending writes, unmaps the sealed segments and closes the store's files
+func (store *mmapStore) closeFiles() error {
+	if err := store.syncer.flush(); err != nil {
+		return err
+	}
+	for _, segment := range store.segments {
+		if err := segment.close(); err != nil {
+			return err
+		}
+	}
+	store.segments = nil
+
+	if err := closeFile(store.sessionFile); err != nil {
+		return err
+	}
+	if err := closeFile(store.senderSeqNumsFile); err != nil {
+		return err
+	}
+	if err := closeFile(store.targetSeqNumsFile); err != nil {
+		return err
+	}
+
+	store.sessionFile = nil
+	store.senderSeqNumsFile = nil
+	store.targetSeqNumsFile = nil
+
+	return nil
+}
+
+// open opens the segment for appending. Records after a torn or incomplete index record, e.g. one that was
+// partially written or refers past the end of the body when the host crashed, are truncated.
+func (segment *mmapSegment) open() (err error) {
+	if segment.bodyFile, err = openOrCreateFile(segment.bodyFname, 0660); err != nil {
+		return err
+	}
+	if segment.indexFile, err = openOrCreateFile(segment.indexFname, 0660); err != nil {
+		return err
+	}
+	// the range of the active segment changes as it is appended to, it is written again when it is sealed
+	if err = removeFile(segment.rangeFname); err != nil {
+		return err
+	}
+
+	info, err := segment.bodyFile.Stat()
+	if err != nil {
+		return err
+	}
+	index, err := ioutil.ReadAll(segment.indexFile)
+	if err != nil {
+		return fmt.Errorf("unable to read file: %s: %s", segment.indexFname, err.Error())
+	}
+
+	segment.index = nil
+	segment.loaded, segment.ranged, segment.count = true, true, 0
+	for i := 0; i+mmapIndexEntrySize <= len(index); i += mmapIndexEntrySize {
+		entry := index[i : i+mmapIndexEntrySize]
+		if int64(binary.BigEndian.Uint32(entry[8:12]))+int64(binary.BigEndian.Uint32(entry[12:16])) > info.Size() {
+			break
+		}
+		segment.append(entry)
+	}
+
+	if len(segment.index) < len(index) {
+		if err := segment.indexFile.Truncate(int64(len(segment.index))); err != nil {
+			return errors.Wrap(err, "truncate torn index")
+		}
+	}
+
+	segment.bodySize = 0
+	if n := segment.len(); n > 0 {
+		entry := segment.entry(n - 1)
+		segment.bodySize = int64(binary.BigEndian.Uint32(entry[8:12])) + int64(binary.BigEndian.Uint32(entry[12:16]))
+	}
+	if segment.bodySize < info.Size() {
+		if err := segment.bodyFile.Truncate(segment.bodySize); err != nil {
+			return errors.Wrap(err, "truncate torn body")
+		}
+	}
+
+	return nil
+}
+
+// mmap maps the body and index of a sealed segment, the active segment is already loaded
+func (segment *mmapSegment) mmap() (err error) {
+	if segment.loaded {
+		return nil
+	}
+
+	if segment.body, err = mmapFile(segment.bodyFname); err != nil {
+		return err
+	}
+	index, err := mmapFile(segment.indexFname)
+	if err != nil {
+		munmapFile(segment.body)
+		segment.body = nil
+		return err
+	}
+
+	segment.mapped = true
+	segment.loaded, segment.ranged, segment.count = true, true, 0
+	// a partial trailing record is ignored by len, the index is unmapped as it was mapped
+	segment.index = index
+	for i, n := 0, segment.len(); i < n; i++ {
+		segment.track(segment.seqNum(i), i == 0)
+	}
+
+	return nil
+}
+
+// loadRange reads the seqnum range of a sealed segment from its range file. A segment with a missing or torn range
+// file is mapped to find its range, which is written for the next time.
+func (segment *mmapSegment) loadRange() error {
+	if segment.ranged {
+		return nil
+	}
+
+	if data, err := ioutil.ReadFile(segment.rangeFname); err == nil {
+		var count, minSeqNum, maxSeqNum int
+		var sorted bool
+		if cnt, err := fmt.Sscanf(string(data), "%d,%d,%d,%t\n", &count, &minSeqNum, &maxSeqNum, &sorted); err == nil && cnt == 4 {
+			segment.count, segment.minSeqNum, segment.maxSeqNum, segment.sorted = count, minSeqNum, maxSeqNum, sorted
+			segment.ranged = true
+			return nil
+		}
+	}
+
+	if err := segment.mmap(); err != nil {
+		return err
+	}
+	return segment.writeRange()
+}
+
+// writeRange writes the range file of a loaded segment
+func (segment *mmapSegment) writeRange() error {
+	data := fmt.Sprintf("%d,%d,%d,%t\n", segment.count, segment.minSeqNum, segment.maxSeqNum, segment.sorted)
+	if err := ioutil.WriteFile(segment.rangeFname, []byte(data), 0660); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", segment.rangeFname, err.Error())
+	}
+	return nil
+}
+
+// append adds an index record to the index of the active segment
+func (segment *mmapSegment) append(entry []byte) {
+	segment.index = append(segment.index, entry...)
+	segment.track(int(binary.BigEndian.Uint64(entry[0:8])), len(segment.index) == mmapIndexEntrySize)
+}
+
+// track updates the seqnum range of the segment with the seqnum of its next index record
+func (segment *mmapSegment) track(seqNum int, first bool) {
+	segment.count++
+	switch {
+	case first:
+		segment.minSeqNum, segment.maxSeqNum, segment.sorted = seqNum, seqNum, true
+	case seqNum <= segment.maxSeqNum:
+		segment.sorted = false
+		if seqNum < segment.minSeqNum {
+			segment.minSeqNum = seqNum
+		}
+	default:
+		segment.maxSeqNum = seqNum
+	}
+}
+
+func (segment *mmapSegment) len() int {
+	return len(segment.index) / mmapIndexEntrySize
+}
+
+func (segment *mmapSegment) entry(i int) []byte {
+	return segment.index[i*mmapIndexEntrySize : (i+1)*mmapIndexEntrySize]
+}
+
+func (segment *mmapSegment) seqNum(i int) int {
+	return int(binary.BigEndian.Uint64(segment.entry(i)[0:8]))
+}
+
+// read returns a copy of the message of the i-th index record
+func (segment *mmapSegment) read(i int) ([]byte, error) {
+	entry := segment.entry(i)
+	offset, size := int64(binary.BigEndian.Uint32(entry[8:12])), int64(binary.BigEndian.Uint32(entry[12:16]))
+
+	msg := make([]byte, size)
+	if segment.mapped {
+		if offset+size > int64(len(segment.body)) {
+			return nil, fmt.Errorf("index record refers past the end of file: %s", segment.bodyFname)
+		}
+		copy(msg, segment.body[offset:offset+size])
+		return msg, nil
+	}
+
+	if _, err := segment.bodyFile.ReadAt(msg, offset); err != nil {
+		return nil, fmt.Errorf("unable to read from file: %s: %s", segment.bodyFname, err.Error())
+	}
+	return msg, nil
+}
+
+func (segment *mmapSegment) close() error {
+	if segment.mapped {
+		if err := munmapFile(segment.body); err != nil {
+			return err
+		}
+		if err := munmapFile(segment.index); err != nil {
+			return err
+		}
+		segment.mapped = false
+	}
+	segment.body, segment.index, segment.loaded, segment.ranged = nil, nil, false, false
+
+	if err := closeFile(segment.bodyFile); err != nil {
+		return err
+	}
+	if err := closeFile(segment.indexFile); err != nil {
+		return err
+	}
+	segment.bodyFile, segment.indexFile = nil, nil
+	return nil
+}
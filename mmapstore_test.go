@@ -0,0 +1,217 @@
+package quickfix
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// MmapStoreTestSuite runs all tests in the MessageStoreTestSuite against the mmap store implementation
+type MmapStoreTestSuite struct {
+	MessageStoreTestSuite
+	mmapStoreRootPath string
+}
+
+func (suite *MmapStoreTestSuite) SetupTest() {
+	suite.mmapStoreRootPath = path.Join(os.TempDir(), fmt.Sprintf("MmapStoreTestSuite-%d", os.Getpid()))
+	mmapStorePath := path.Join(suite.mmapStoreRootPath, fmt.Sprintf("%d", time.Now().UnixNano()))
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	// create settings, a small segment size seals segments within the suite
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+MmapStorePath=%s
+MmapStoreSegmentSize=32
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, mmapStorePath, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	// create store
+	suite.msgStore, err = NewMmapStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *MmapStoreTestSuite) TearDownTest() {
+	suite.msgStore.Close()
+	os.RemoveAll(suite.mmapStoreRootPath)
+}
+
+func TestMmapStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(MmapStoreTestSuite))
+}
+
+func newTestMmapStore(t *testing.T, segmentSize int64) (*mmapStore, string) {
+	mmapStorePath := path.Join(os.TempDir(), fmt.Sprintf("MmapStoreTest-%d-%d", os.Getpid(), time.Now().UnixNano()))
+	t.Cleanup(func() { os.RemoveAll(mmapStorePath) })
+
+	store, err := newMmapStore(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}, mmapStorePath, segmentSize, &fileStoreSyncer{dirty: make(map[*os.File]bool)})
+	require.Nil(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store, mmapStorePath
+}
+
+func TestMmapStore_Segments(t *testing.T) {
+	store, mmapStorePath := newTestMmapStore(t, 8)
+
+	var expected [][]byte
+	for seqNum := 1; seqNum <= 10; seqNum++ {
+		msg := []byte(fmt.Sprintf("msg%02d", seqNum))
+		expected = append(expected, msg)
+		require.Nil(t, store.SaveMessage(seqNum, msg))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+
+	bodies, err := filepath.Glob(path.Join(mmapStorePath, "*.body"))
+	require.Nil(t, err)
+	assert.Len(t, bodies, 10, "each message should be sealed in its own segment")
+
+	msgs, err := store.GetMessages(1, 10)
+	require.Nil(t, err)
+	assert.Equal(t, expected, msgs)
+
+	msgs, err = store.GetMessages(4, 6)
+	require.Nil(t, err)
+	assert.Equal(t, expected[3:6], msgs)
+
+	require.Nil(t, store.Refresh())
+	assert.True(t, store.active().loaded)
+	for _, segment := range store.segments[:len(store.segments)-1] {
+		assert.False(t, segment.loaded, "sealed segments should not be read on refresh")
+	}
+	assert.Equal(t, 11, store.NextSenderMsgSeqNum())
+
+	msgs, err = store.GetMessages(1, 10)
+	require.Nil(t, err)
+	assert.Equal(t, expected, msgs)
+
+	require.Nil(t, store.Reset())
+	bodies, err = filepath.Glob(path.Join(mmapStorePath, "*.body"))
+	require.Nil(t, err)
+	assert.Len(t, bodies, 1)
+}
+
+func TestMmapStore_SaveMessageAgain(t *testing.T) {
+	store, _ := newTestMmapStore(t, 12)
+
+	require.Nil(t, store.SaveMessage(2, []byte("two")))
+	require.Nil(t, store.SaveMessage(1, []byte("one")))
+	require.Nil(t, store.SaveMessage(3, []byte("three")))
+	require.Nil(t, store.SaveMessage(2, []byte("TWO")))
+
+	expected := [][]byte{[]byte("one"), []byte("TWO"), []byte("three")}
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, expected, msgs)
+
+	require.Nil(t, store.Refresh())
+	msgs, err = store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, expected, msgs)
+}
+
+func TestMmapStore_RecoverTornRecord(t *testing.T) {
+	store, mmapStorePath := newTestMmapStore(t, 1024)
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+	}
+	active := store.active()
+	require.Nil(t, store.Close())
+
+	// simulate a crash while saving message 3: the body is torn, the index record is partially written and the
+	// seqnums write did not reach the disk
+	require.Nil(t, os.Truncate(active.indexFname, 2*mmapIndexEntrySize+mmapIndexEntrySize/2))
+	require.Nil(t, os.Truncate(active.bodyFname, 10))
+	require.Nil(t, os.Truncate(store.senderSeqNumsFname, 0))
+
+	recovered, err := newMmapStore(store.sessionID, mmapStorePath, 1024, &fileStoreSyncer{dirty: make(map[*os.File]bool)})
+	require.Nil(t, err)
+	defer recovered.Close()
+
+	msgs, err := recovered.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2")}, msgs)
+	assert.Equal(t, 3, recovered.NextSenderMsgSeqNum(), "seqnum should follow the stored messages")
+
+	info, err := os.Stat(active.indexFname)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2*mmapIndexEntrySize), info.Size())
+
+	require.Nil(t, recovered.SaveMessage(3, []byte("msg3")))
+	require.Nil(t, recovered.Refresh())
+	msgs, err = recovered.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3")}, msgs)
+}
+
+func TestMmapStore_LoweredSeqNumSurvivesRestart(t *testing.T) {
+	store, mmapStorePath := newTestMmapStore(t, 1024)
+
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.SetNextSenderMsgSeqNum(2))
+	require.Nil(t, store.Close())
+
+	restarted, err := newMmapStore(store.sessionID, mmapStorePath, 1024, &fileStoreSyncer{dirty: make(map[*os.File]bool)})
+	require.Nil(t, err)
+	defer restarted.Close()
+
+	assert.Equal(t, 2, restarted.NextSenderMsgSeqNum())
+}
+
+func TestMmapStore_GetMessagesMapsOverlappingSegments(t *testing.T) {
+	store, mmapStorePath := newTestMmapStore(t, 8)
+
+	var expected [][]byte
+	for seqNum := 1; seqNum <= 10; seqNum++ {
+		msg := []byte(fmt.Sprintf("msg%02d", seqNum))
+		expected = append(expected, msg)
+		require.Nil(t, store.SaveMessage(seqNum, msg))
+		require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	}
+	require.Nil(t, store.Close())
+
+	for _, removeRanges := range []bool{false, true} {
+		if removeRanges {
+			// segments without a range file are mapped once to find their range
+			ranges, err := filepath.Glob(path.Join(mmapStorePath, "*.range"))
+			require.Nil(t, err)
+			require.NotEmpty(t, ranges)
+			for _, fname := range ranges {
+				require.Nil(t, os.Remove(fname))
+			}
+		}
+
+		restarted, err := newMmapStore(store.sessionID, mmapStorePath, 8, &fileStoreSyncer{dirty: make(map[*os.File]bool)})
+		require.Nil(t, err)
+
+		msgs, err := restarted.GetMessages(8, 9)
+		require.Nil(t, err)
+		assert.Equal(t, expected[7:9], msgs)
+		if !removeRanges {
+			for _, segment := range restarted.segments[:len(restarted.segments)-1] {
+				seqNum := segment.minSeqNum
+				assert.Equal(t, seqNum >= 8 && seqNum <= 9, segment.mapped, "segment of %d", seqNum)
+			}
+		}
+
+		msgs, err = restarted.GetMessages(1, 10)
+		require.Nil(t, err)
+		assert.Equal(t, expected, msgs)
+		require.Nil(t, restarted.Close())
+	}
+}
@@ -0,0 +1,161 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+type mongoLogFactory struct {
+	settings           *Settings
+	messagesCollection string
+	eventsCollection   string
+}
+
+type mongoLog struct {
+	sessionID          SessionID
+	mongoDatabase      string
+	db                 *mgo.Session
+	messagesCollection string
+	eventsCollection   string
+}
+
+// NewMongoLogFactory returns a mongo-based implementation of LogFactory, configured with the MongoStoreConnection
+// and MongoStoreDatabase settings
+func NewMongoLogFactory(settings *Settings) LogFactory {
+	return NewMongoLogFactoryPrefixed(settings, "")
+}
+
+// NewMongoLogFactoryPrefixed returns a mongo-based implementation of LogFactory, with prefix on collections
+func NewMongoLogFactoryPrefixed(settings *Settings, collectionsPrefix string) LogFactory {
+	return mongoLogFactory{
+		settings:           settings,
+		messagesCollection: collectionsPrefix + "messages_log",
+		eventsCollection:   collectionsPrefix + "event_log",
+	}
+}
+
+// Create creates a global log. Its session ID fields are empty.
+func (f mongoLogFactory) Create() (Log, error) {
+	return f.newMongoLog(SessionID{}, f.settings.GlobalSettings())
+}
+
+// CreateSessionLog creates a log for the session
+func (f mongoLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %v", sessionID)
+	}
+
+	return f.newMongoLog(sessionID, sessionSettings)
+}
+
+func (f mongoLogFactory) newMongoLog(sessionID SessionID, settings *SessionSettings) (*mongoLog, error) {
+	mongoConnectionURL, err := settings.Setting(config.MongoStoreConnection)
+	if err != nil {
+		return nil, err
+	}
+	mongoDatabase, err := settings.Setting(config.MongoStoreDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &mongoLog{
+		sessionID:          sessionID,
+		mongoDatabase:      mongoDatabase,
+		messagesCollection: f.messagesCollection,
+		eventsCollection:   f.eventsCollection,
+	}
+
+	if l.db, err = mgo.Dial(mongoConnectionURL); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+const (
+	mongoLogIncoming = "incoming"
+	mongoLogOutgoing = "outgoing"
+)
+
+type mongoLogEntryData struct {
+	Time time.Time `bson:"time"`
+
+	//Message specific data
+	Direction string `bson:"direction,omitempty"`
+	MsgType   string `bson:"msg_type,omitempty"`
+	MsgSeqNum int    `bson:"msg_seq_num,omitempty"`
+
+	Text string `bson:"text"`
+
+	//Indexed data
+	BeginString      string `bson:"begin_string"`
+	SessionQualifier string `bson:"session_qualifier"`
+	SenderCompID     string `bson:"sender_comp_id"`
+	SenderSubID      string `bson:"sender_sub_id"`
+	SenderLocID      string `bson:"sender_loc_id"`
+	TargetCompID     string `bson:"target_comp_id"`
+	TargetSubID      string `bson:"target_sub_id"`
+	TargetLocID      string `bson:"target_loc_id"`
+}
+
+func newMongoLogEntry(s SessionID, text string) *mongoLogEntryData {
+	return &mongoLogEntryData{
+		Time:             time.Now().UTC(),
+		Text:             text,
+		BeginString:      s.BeginString,
+		SessionQualifier: s.Qualifier,
+		SenderCompID:     s.SenderCompID,
+		SenderSubID:      s.SenderSubID,
+		SenderLocID:      s.SenderLocationID,
+		TargetCompID:     s.TargetCompID,
+		TargetSubID:      s.TargetSubID,
+		TargetLocID:      s.TargetLocationID,
+	}
+}
+
+// newMongoLogMessageEntry returns the entry of a message. MsgType and MsgSeqNum are left empty if the message
+// cannot be parsed.
+func newMongoLogMessageEntry(s SessionID, direction string, msg []byte) *mongoLogEntryData {
+	entry := newMongoLogEntry(s, string(msg))
+	entry.Direction = direction
+
+	parsed := NewMessage()
+	if err := ParseMessage(parsed, bytes.NewBuffer(msg)); err == nil {
+		entry.MsgType, _ = parsed.MsgType()
+		entry.MsgSeqNum, _ = parsed.Header.GetInt(tagMsgSeqNum)
+	}
+
+	return entry
+}
+
+// insert writes entry to collection. Errors are dropped, as the Log interface has no way to report them.
+func (l *mongoLog) insert(collection string, entry *mongoLogEntryData) {
+	_ = l.db.DB(l.mongoDatabase).C(collection).Insert(entry)
+}
+
+func (l *mongoLog) OnIncoming(msg []byte) {
+	l.insert(l.messagesCollection, newMongoLogMessageEntry(l.sessionID, mongoLogIncoming, msg))
+}
+
+func (l *mongoLog) OnOutgoing(msg []byte) {
+	l.insert(l.messagesCollection, newMongoLogMessageEntry(l.sessionID, mongoLogOutgoing, msg))
+}
+
+func (l *mongoLog) OnEvent(msg string) {
+	l.insert(l.eventsCollection, newMongoLogEntry(l.sessionID, msg))
+}
+
+func (l *mongoLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+// Close closes the log's database connection
+func (l *mongoLog) Close() error {
+	l.db.Close()
+	return nil
+}
@@ -0,0 +1,117 @@
+package quickfix
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMongoLogMessageEntry(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET", Qualifier: "Q"}
+	msg := []byte("8=FIX.4.4\x019=56\x0135=0\x0134=12\x0149=SENDER\x0152=20161012-17:05:28.000\x0156=TARGET\x0110=133\x01")
+
+	entry := newMongoLogMessageEntry(sessionID, mongoLogIncoming, msg)
+	assert.Equal(t, mongoLogIncoming, entry.Direction)
+	assert.Equal(t, "0", entry.MsgType)
+	assert.Equal(t, 12, entry.MsgSeqNum)
+	assert.Equal(t, string(msg), entry.Text)
+	assert.Equal(t, "SENDER", entry.SenderCompID)
+	assert.Equal(t, "Q", entry.SessionQualifier)
+	assert.WithinDuration(t, time.Now(), entry.Time, time.Minute)
+
+	entry = newMongoLogMessageEntry(sessionID, mongoLogOutgoing, []byte("garbled"))
+	assert.Equal(t, mongoLogOutgoing, entry.Direction)
+	assert.Empty(t, entry.MsgType)
+	assert.Equal(t, "garbled", entry.Text)
+}
+
+// MongoLogTestSuite runs against the MongoDB instance in MONGODB_TEST_CXN
+type MongoLogTestSuite struct {
+	suite.Suite
+	db            *mgo.Session
+	mongoDatabase string
+	sessionID     SessionID
+	log           Log
+}
+
+func (suite *MongoLogTestSuite) SetupTest() {
+	mongoDbCxn := os.Getenv("MONGODB_TEST_CXN")
+	if len(mongoDbCxn) <= 0 {
+		log.Println("MONGODB_TEST_CXN environment arg is not provided, skipping...")
+		suite.T().SkipNow()
+	}
+	suite.mongoDatabase = "automated_testing_database"
+
+	// create settings
+	suite.sessionID = SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+MongoStoreConnection=%s
+MongoStoreDatabase=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, mongoDbCxn, suite.mongoDatabase, suite.sessionID.BeginString, suite.sessionID.SenderCompID, suite.sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.db, err = mgo.Dial(mongoDbCxn)
+	require.Nil(suite.T(), err)
+	for _, collection := range []string{"test_messages_log", "test_event_log"} {
+		_, err = suite.db.DB(suite.mongoDatabase).C(collection).RemoveAll(nil)
+		require.Nil(suite.T(), err)
+	}
+
+	// create log
+	suite.log, err = NewMongoLogFactoryPrefixed(settings, "test_").CreateSessionLog(suite.sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *MongoLogTestSuite) TearDownTest() {
+	if suite.log != nil {
+		suite.log.(io.Closer).Close()
+	}
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+func (suite *MongoLogTestSuite) TestMessages() {
+	msg := []byte("8=FIX.4.4\x019=56\x0135=0\x0134=12\x0149=SENDER\x0152=20161012-17:05:28.000\x0156=TARGET\x0110=133\x01")
+	suite.log.OnIncoming(msg)
+	suite.log.OnOutgoing(msg)
+
+	var entries []mongoLogEntryData
+	err := suite.db.DB(suite.mongoDatabase).C("test_messages_log").Find(bson.M{"sender_comp_id": "SENDER"}).Sort("time").All(&entries)
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), entries, 2)
+	suite.Equal(mongoLogIncoming, entries[0].Direction)
+	suite.Equal(mongoLogOutgoing, entries[1].Direction)
+	suite.Equal("0", entries[0].MsgType)
+	suite.Equal(12, entries[0].MsgSeqNum)
+}
+
+func (suite *MongoLogTestSuite) TestEvents() {
+	suite.log.OnEventf("event %d", 1)
+
+	var entries []mongoLogEntryData
+	err := suite.db.DB(suite.mongoDatabase).C("test_event_log").Find(nil).All(&entries)
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), entries, 1)
+	suite.Equal("event 1", entries[0].Text)
+	suite.Equal("TARGET", entries[0].TargetCompID)
+}
+
+func TestMongoLogTestSuite(t *testing.T) {
+	suite.Run(t, new(MongoLogTestSuite))
+}
@@ -239,6 +239,20 @@ func (store *mongoStore) CreationTime() time.Time {
 	return store.cache.CreationTime()
 }
 
+// SetCreationTime sets the creation time of the store
+func (store *mongoStore) SetCreationTime(t time.Time) error {
+	msgFilter := generateMessageFilter(&store.sessionID)
+	sessionUpdate := generateMessageFilter(&store.sessionID)
+	sessionUpdate.IncomingSeqNum = store.cache.NextTargetMsgSeqNum()
+	sessionUpdate.OutgoingSeqNum = store.cache.NextSenderMsgSeqNum()
+	sessionUpdate.CreationTime = t
+	if err := store.db.DB(store.mongoDatabase).C(store.sessionsCollection).Update(msgFilter, sessionUpdate); err != nil {
+		return err
+	}
+	store.cache.creationTime = t
+	return nil
+}
+
 func (store *mongoStore) SaveMessage(seqNum int, msg []byte) (err error) {
 	msgFilter := generateMessageFilter(&store.sessionID)
 	msgFilter.Msgseq = seqNum
@@ -247,7 +261,14 @@ func (store *mongoStore) SaveMessage(seqNum int, msg []byte) (err error) {
 	return
 }
 
-func (store *mongoStore) GetMessages(beginSeqNum, endSeqNum int) (msgs [][]byte, err error) {
+// IterateMessages reads the documents of the range in batches, each batch is read and its cursor closed before fn
+// is called, so fn can use the store
+func (store *mongoStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
+	return iterateBatches(beginSeqNum, endSeqNum, store.readMessages, fn)
+}
+
+// readMessages reads the documents of the range
+func (store *mongoStore) readMessages(beginSeqNum, endSeqNum int, add func(seqNum int, msg []byte)) (err error) {
 	msgFilter := generateMessageFilter(&store.sessionID)
 	//Marshal into database form
 	msgFilterBytes, err := bson.Marshal(msgFilter)
@@ -267,12 +288,20 @@ func (store *mongoStore) GetMessages(beginSeqNum, endSeqNum int) (msgs [][]byte,
 
 	iter := store.db.DB(store.mongoDatabase).C(store.messagesCollection).Find(seqFilter).Sort("msgseq").Iter()
 	for iter.Next(msgFilter) {
-		msgs = append(msgs, msgFilter.Message)
+		add(msgFilter.Msgseq, append([]byte(nil), msgFilter.Message...))
 	}
 	err = iter.Close()
 	return
 }
 
+func (store *mongoStore) GetMessages(beginSeqNum, endSeqNum int) (msgs [][]byte, err error) {
+	err = store.IterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	})
+	return
+}
+
 // Close closes the store's database connection
 func (store *mongoStore) Close() error {
 	if store.db != nil {
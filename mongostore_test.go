@@ -45,7 +45,9 @@ TargetCompID=%s`, mongoDbCxn, mongoDatabase, sessionID.BeginString, sessionID.Se
 }
 
 func (suite *MongoStoreTestSuite) TearDownTest() {
-	suite.msgStore.Close()
+	if suite.msgStore != nil {
+		suite.msgStore.Close()
+	}
 }
 
 func TestMongoStoreTestSuite(t *testing.T) {
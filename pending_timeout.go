@@ -9,7 +9,8 @@ type pendingTimeout struct {
 func (s pendingTimeout) Timeout(session *session, event internal.Event) (nextState sessionState) {
 	switch event {
 	case internal.PeerTimeout:
-		session.log.OnEvent("Session Timeout")
+		session.logEvent(LogLevelError, LogCodeHeartbeatTimeout, "Session Timeout")
+		session.notify(HeartbeatTimeoutEvent{})
 		return latentState{}
 	}
 
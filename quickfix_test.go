@@ -164,18 +164,19 @@ func (m *MessageFactory) SequenceReset(seqNo int) *Message {
 }
 
 type MockSessionReceiver struct {
-	sendChannel chan []byte
+	sendChannel chan fixOut
 }
 
 func newMockSessionReceiver() MockSessionReceiver {
 	return MockSessionReceiver{
-		sendChannel: make(chan []byte, 10),
+		sendChannel: make(chan fixOut, 10),
 	}
 }
 
 func (p *MockSessionReceiver) LastMessage() (msg []byte, ok bool) {
 	select {
-	case msg, ok = <-p.sendChannel:
+	case out, open := <-p.sendChannel:
+		msg, ok = out.bytes, open
 	default:
 		ok = true
 	}
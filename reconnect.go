@@ -0,0 +1,128 @@
+package quickfix
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+//ReconnectFailureHandler may be implemented by an Application to be notified when an initiator gives up reconnecting
+//a session after MaxReconnectAttempts consecutive failed connection attempts.
+type ReconnectFailureHandler interface {
+	OnReconnectFailed(sessionID SessionID, attempts int)
+}
+
+//reconnectStrategy decides which address an initiator session connects to and how long to wait between attempts
+type reconnectStrategy struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	jitter      int
+	maxAttempts int
+	failback    bool
+	addresses   []string
+
+	//index of the address of the next attempt
+	index int
+
+	//consecutive failed attempts
+	failures int
+
+	random func() float64
+}
+
+func newReconnectStrategy(s *session) *reconnectStrategy {
+	return &reconnectStrategy{
+		interval:    s.ReconnectInterval,
+		maxInterval: s.ReconnectIntervalMax,
+		jitter:      s.ReconnectJitter,
+		maxAttempts: s.MaxReconnectAttempts,
+		failback:    s.SocketConnectFailback,
+		addresses:   s.SocketConnectAddress,
+		random:      rand.Float64,
+	}
+}
+
+//address returns the address of the next attempt
+func (r *reconnectStrategy) address() string {
+	return r.addresses[r.index]
+}
+
+//primary returns the preferred address
+func (r *reconnectStrategy) primary() string {
+	return r.addresses[0]
+}
+
+//onBackup returns true if connected to a backup address that should fail back to the primary
+func (r *reconnectStrategy) onBackup() bool {
+	return r.failback && r.index > 0
+}
+
+//failed records a failed connection attempt, returning false if no more attempts should be made
+func (r *reconnectStrategy) failed() bool {
+	r.failures++
+	r.index = (r.index + 1) % len(r.addresses)
+
+	return r.maxAttempts == 0 || r.failures < r.maxAttempts
+}
+
+//disconnected records the end of an established connection
+func (r *reconnectStrategy) disconnected() {
+	r.failures = 0
+
+	if r.failback {
+		r.index = 0
+	} else {
+		r.index = (r.index + 1) % len(r.addresses)
+	}
+}
+
+//wait returns the time to wait before the next attempt. With a maximum interval, the wait doubles with each
+//consecutive failure. Jitter varies the wait randomly up or down by a percentage.
+func (r *reconnectStrategy) wait() time.Duration {
+	wait := r.interval
+
+	if r.maxInterval > 0 {
+		for n := 1; n < r.failures && wait < r.maxInterval; n++ {
+			wait *= 2
+		}
+
+		if wait > r.maxInterval {
+			wait = r.maxInterval
+		}
+	}
+
+	if r.jitter > 0 {
+		delta := float64(wait) * float64(r.jitter) / 100
+		wait += time.Duration(delta * (2*r.random() - 1))
+	}
+
+	return wait
+}
+
+//probeAddress closes the returned channel once a connection to address succeeds. A connection is attempted every
+//interval until stop is closed. Successful connections are closed immediately.
+func probeAddress(dialer proxy.Dialer, address string, interval time.Duration, stop <-chan struct{}) <-chan struct{} {
+	reachable := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+
+			if conn, err := dialer.Dial("tcp", address); err == nil {
+				conn.Close()
+				close(reachable)
+				return
+			}
+		}
+	}()
+
+	return reachable
+}
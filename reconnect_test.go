@@ -0,0 +1,141 @@
+package quickfix
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+)
+
+func TestReconnectStrategy_FixedInterval(t *testing.T) {
+	r := &reconnectStrategy{interval: 30 * time.Second, addresses: []string{"a:1", "b:2"}}
+
+	assert.Equal(t, "a:1", r.address())
+	assert.True(t, r.failed())
+	assert.Equal(t, "b:2", r.address())
+	assert.Equal(t, 30*time.Second, r.wait())
+	assert.True(t, r.failed())
+	assert.Equal(t, "a:1", r.address())
+	assert.Equal(t, 30*time.Second, r.wait())
+
+	r.disconnected()
+	assert.Equal(t, "b:2", r.address(), "round robin should move on after a disconnect")
+}
+
+func TestReconnectStrategy_Backoff(t *testing.T) {
+	r := &reconnectStrategy{interval: time.Second, maxInterval: 10 * time.Second, addresses: []string{"a:1"}}
+
+	var waits []time.Duration
+	for n := 0; n < 6; n++ {
+		r.failed()
+		waits = append(waits, r.wait())
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}, waits)
+
+	r.disconnected()
+	assert.Equal(t, time.Second, r.wait(), "backoff should reset after a connection")
+}
+
+func TestReconnectStrategy_Jitter(t *testing.T) {
+	r := &reconnectStrategy{interval: 10 * time.Second, jitter: 20, addresses: []string{"a:1"}}
+
+	r.random = func() float64 { return 0 }
+	assert.Equal(t, 8*time.Second, r.wait())
+
+	r.random = func() float64 { return 1 }
+	assert.Equal(t, 12*time.Second, r.wait())
+
+	r.random = func() float64 { return 0.5 }
+	assert.Equal(t, 10*time.Second, r.wait())
+}
+
+func TestReconnectStrategy_MaxAttempts(t *testing.T) {
+	r := &reconnectStrategy{interval: time.Second, maxAttempts: 3, addresses: []string{"a:1"}}
+
+	assert.True(t, r.failed())
+	assert.True(t, r.failed())
+	assert.False(t, r.failed())
+	assert.Equal(t, 3, r.failures)
+}
+
+func TestReconnectStrategy_Failback(t *testing.T) {
+	r := &reconnectStrategy{interval: time.Second, failback: true, addresses: []string{"primary:1", "backup:2", "backup:3"}}
+
+	assert.False(t, r.onBackup())
+	r.failed()
+	assert.Equal(t, "backup:2", r.address())
+	assert.True(t, r.onBackup())
+
+	r.disconnected()
+	assert.Equal(t, "primary:1", r.address(), "reconnects should start with the primary")
+	assert.False(t, r.onBackup())
+}
+
+type reconnectFailureApp struct {
+	*MockApp
+	failed chan int
+}
+
+func (a reconnectFailureApp) OnReconnectFailed(sessionID SessionID, attempts int) {
+	a.failed <- attempts
+}
+
+func TestInitiator_MaxReconnectAttempts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+	settings.GlobalSettings().Set(config.SocketConnectHost, "127.0.0.1")
+	settings.GlobalSettings().Set(config.SocketConnectPort, strconv.Itoa(port))
+	settings.GlobalSettings().Set(config.ReconnectInterval, "1")
+	settings.GlobalSettings().Set(config.MaxReconnectAttempts, "2")
+
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIX42)
+	sessionSettings.Set(config.SenderCompID, "initiator")
+	sessionSettings.Set(config.TargetCompID, "unreachable")
+	_, err = settings.AddSession(sessionSettings)
+	require.Nil(t, err)
+
+	app := reconnectFailureApp{MockApp: &MockApp{}, failed: make(chan int, 1)}
+	initiator, err := NewInitiator(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.Nil(t, err)
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	select {
+	case attempts := <-app.failed:
+		assert.Equal(t, 2, attempts)
+	case <-time.After(5 * time.Second):
+		t.Fatal("application should be notified after MaxReconnectAttempts")
+	}
+}
+
+func TestProbeAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	select {
+	case <-probeAddress(proxy.Direct, listener.Addr().String(), 10*time.Millisecond, stop):
+	case <-time.After(5 * time.Second):
+		t.Fatal("address should be reachable")
+	}
+}
@@ -0,0 +1,234 @@
+package quickfix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redisError is an error reply of the redis server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisClient is a minimal client of the redis RESP protocol. A broken connection is dropped and redialed by the
+// next command.
+type redisClient struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+
+	mutex sync.Mutex
+	conn  net.Conn
+	rd    *bufio.Reader
+}
+
+func newRedisClient(address, password string, db int, timeout time.Duration) (*redisClient, error) {
+	c := &redisClient{address: address, password: password, db: db, timeout: timeout}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *redisClient) connect() error {
+	conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+
+	var cmds [][]string
+	if c.password != "" {
+		cmds = append(cmds, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	replies, err := c.roundTrip(cmds)
+	if err == nil {
+		err = redisReplyError(replies)
+	}
+	if err != nil {
+		c.drop()
+	}
+	return err
+}
+
+func (c *redisClient) drop() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn, c.rd = nil, nil
+}
+
+// do sends a command and returns its reply. An error reply is returned as a redisError.
+func (c *redisClient) do(args ...string) (interface{}, error) {
+	replies, err := c.pipeline([][]string{args})
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := replies[0].(redisError); ok {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends the commands at once and returns their replies, error replies are returned in place as a redisError
+func (c *redisClient) pipeline(cmds [][]string) ([]interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	replies, err := c.roundTrip(cmds)
+	if err != nil {
+		c.drop()
+		return nil, err
+	}
+	return replies, nil
+}
+
+func (c *redisClient) roundTrip(cmds [][]string) ([]interface{}, error) {
+	if c.timeout > 0 {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	w := bufio.NewWriter(c.conn)
+	for _, args := range cmds {
+		fmt.Fprintf(w, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(cmds))
+	for i := range cmds {
+		reply, err := readRedisReply(c.rd)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+// Close closes the connection
+func (c *redisClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+// readRedisReply reads a RESP reply: simple strings as string, errors as redisError, integers as int64, bulk
+// strings as []byte and arrays as []interface{}. Null bulk strings and arrays are nil.
+func readRedisReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := readRedisLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return redisError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		array := make([]interface{}, n)
+		for i := range array {
+			if array[i], err = readRedisReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	}
+
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+func readRedisLine(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// redisReplyError returns the first error reply of a pipeline, or of the array reply of an EXEC
+func redisReplyError(replies []interface{}) error {
+	for _, reply := range replies {
+		switch r := reply.(type) {
+		case redisError:
+			return r
+		case []interface{}:
+			if err := redisReplyError(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// redisString returns a bulk or simple string reply as a string
+func redisString(reply interface{}) string {
+	switch r := reply.(type) {
+	case []byte:
+		return string(r)
+	case string:
+		return r
+	}
+	return ""
+}
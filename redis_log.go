@@ -0,0 +1,97 @@
+package quickfix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+type redisLogFactory struct {
+	settings *Settings
+}
+
+type redisLog struct {
+	client *redisClient
+	key    string
+	maxLen int
+}
+
+// NewRedisLogFactory returns a redis-based implementation of LogFactory. Messages and events of a session are added
+// to a stream with the fields time, type (incoming, outgoing or event) and text.
+func NewRedisLogFactory(settings *Settings) LogFactory {
+	return redisLogFactory{settings: settings}
+}
+
+// Create creates a global log, added to the global:log stream
+func (f redisLogFactory) Create() (Log, error) {
+	return newRedisLogFromSettings(f.settings.GlobalSettings(), "global")
+}
+
+// CreateSessionLog creates a log for the session
+func (f redisLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %v", sessionID)
+	}
+
+	return newRedisLogFromSettings(sessionSettings, sessionIDFilenamePrefix(sessionID))
+}
+
+func newRedisLogFromSettings(settings *SessionSettings, name string) (Log, error) {
+	var maxLen int
+	if settings.HasSetting(config.RedisLogMaxLen) {
+		var err error
+		if maxLen, err = settings.IntSetting(config.RedisLogMaxLen); err != nil {
+			return nil, err
+		}
+		if maxLen < 0 {
+			return nil, errors.New("RedisLogMaxLen must not be negative")
+		}
+	}
+
+	client, keyPrefix, err := newRedisClientFromSettings(settings,
+		config.RedisLogAddress, config.RedisLogPassword, config.RedisLogDB, config.RedisLogKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisLog{client: client, key: keyPrefix + name + ":log", maxLen: maxLen}, nil
+}
+
+// add appends an entry to the stream. Errors are dropped, as the Log interface has no way to report them.
+func (l *redisLog) add(entryType string, text string) {
+	args := []string{"XADD", l.key}
+	if l.maxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.Itoa(l.maxLen))
+	}
+	args = append(args, "*",
+		"time", time.Now().UTC().Format(time.RFC3339Nano),
+		"type", entryType,
+		"text", text)
+
+	_, _ = l.client.do(args...)
+}
+
+func (l *redisLog) OnIncoming(msg []byte) {
+	l.add("incoming", string(msg))
+}
+
+func (l *redisLog) OnOutgoing(msg []byte) {
+	l.add("outgoing", string(msg))
+}
+
+func (l *redisLog) OnEvent(msg string) {
+	l.add("event", msg)
+}
+
+func (l *redisLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+// Close closes the log's connection
+func (l *redisLog) Close() error {
+	return l.client.Close()
+}
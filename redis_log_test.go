@@ -0,0 +1,56 @@
+package quickfix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLog(t *testing.T) {
+	server := newFakeRedisServer(t, "")
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+RedisLogAddress=%s
+RedisLogMaxLen=2
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, server.addr(), sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(t, err)
+
+	factory := NewRedisLogFactory(settings)
+
+	globalLog, err := factory.Create()
+	require.Nil(t, err)
+	defer closeLog(globalLog)
+	globalLog.OnEvent("started")
+
+	entries := server.entries("quickfix:global:log")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "event", entries[0]["type"])
+	assert.Equal(t, "started", entries[0]["text"])
+	assert.NotEmpty(t, entries[0]["time"])
+
+	sessionLog, err := factory.CreateSessionLog(sessionID)
+	require.Nil(t, err)
+	defer closeLog(sessionLog)
+
+	sessionLog.OnEventf("hello %s", "world")
+	sessionLog.OnIncoming([]byte("8=FIX.4.4\x019=5\x01"))
+	sessionLog.OnOutgoing([]byte("8=FIX.4.4\x019=6\x01"))
+
+	entries = server.entries("quickfix:FIX.4.4-SENDER-TARGET:log")
+	require.Len(t, entries, 2, "the stream should be capped by RedisLogMaxLen")
+	assert.Equal(t, "incoming", entries[0]["type"])
+	assert.Equal(t, "8=FIX.4.4\x019=5\x01", entries[0]["text"])
+	assert.Equal(t, "outgoing", entries[1]["type"])
+
+	_, err = factory.CreateSessionLog(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "UNKNOWN"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,301 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRedisMember struct {
+	score  int
+	member string
+}
+
+// fakeRedisServer is an in-process server of the RESP commands used by the redis store and log
+type fakeRedisServer struct {
+	listener net.Listener
+	password string
+
+	mutex   sync.Mutex
+	hashes  map[string]map[string]string
+	zsets   map[string][]fakeRedisMember
+	streams map[string][][]string
+	nextID  int
+}
+
+func newFakeRedisServer(t *testing.T, password string) *fakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	s := &fakeRedisServer{
+		listener: listener,
+		password: password,
+		hashes:   make(map[string]map[string]string),
+		zsets:    make(map[string][]fakeRedisMember),
+		streams:  make(map[string][][]string),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeRedisServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRedisServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	rd := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	var queued [][]string
+	inMulti := false
+
+	for {
+		cmd, err := readFakeRedisCommand(rd)
+		if err != nil {
+			return
+		}
+
+		name := strings.ToUpper(cmd[0])
+		var reply string
+		switch {
+		case name == "AUTH":
+			if len(cmd) == 2 && cmd[1] == s.password {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			reply = "+OK\r\n"
+		case name == "EXEC":
+			replies := make([]string, len(queued))
+			s.mutex.Lock()
+			for i, queuedCmd := range queued {
+				replies[i] = s.execute(queuedCmd)
+			}
+			s.mutex.Unlock()
+			inMulti, queued = false, nil
+			reply = fmt.Sprintf("*%d\r\n%s", len(replies), strings.Join(replies, ""))
+		case inMulti:
+			queued = append(queued, cmd)
+			reply = "+QUEUED\r\n"
+		default:
+			s.mutex.Lock()
+			reply = s.execute(cmd)
+			s.mutex.Unlock()
+		}
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readFakeRedisCommand(rd *bufio.Reader) ([]string, error) {
+	reply, err := readRedisReply(rd)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := reply.([]interface{})
+	if !ok || len(array) == 0 {
+		return nil, fmt.Errorf("unexpected command %v", reply)
+	}
+
+	cmd := make([]string, len(array))
+	for i, arg := range array {
+		cmd[i] = redisString(arg)
+	}
+	return cmd, nil
+}
+
+func fakeRedisBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func fakeRedisArray(values []string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(values))
+	for _, value := range values {
+		reply += fakeRedisBulk(value)
+	}
+	return reply
+}
+
+func (s *fakeRedisServer) execute(cmd []string) string {
+	switch strings.ToUpper(cmd[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range cmd[1:] {
+			if _, ok := s.hashes[key]; ok {
+				deleted++
+			}
+			if _, ok := s.zsets[key]; ok {
+				deleted++
+			}
+			delete(s.hashes, key)
+			delete(s.zsets, key)
+			delete(s.streams, key)
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "HSET":
+		hash, ok := s.hashes[cmd[1]]
+		if !ok {
+			hash = make(map[string]string)
+			s.hashes[cmd[1]] = hash
+		}
+		for i := 2; i+1 < len(cmd); i += 2 {
+			hash[cmd[i]] = cmd[i+1]
+		}
+		return fmt.Sprintf(":%d\r\n", (len(cmd)-2)/2)
+	case "HGETALL":
+		var values []string
+		for field, value := range s.hashes[cmd[1]] {
+			values = append(values, field, value)
+		}
+		return fakeRedisArray(values)
+	case "ZADD":
+		score, _ := strconv.Atoi(cmd[2])
+		zset := append(s.zsets[cmd[1]], fakeRedisMember{score: score, member: cmd[3]})
+		sort.SliceStable(zset, func(i, j int) bool { return zset[i].score < zset[j].score })
+		s.zsets[cmd[1]] = zset
+		return ":1\r\n"
+	case "ZREMRANGEBYSCORE":
+		min, _ := strconv.Atoi(cmd[2])
+		max, _ := strconv.Atoi(cmd[3])
+		var kept []fakeRedisMember
+		for _, m := range s.zsets[cmd[1]] {
+			if m.score < min || m.score > max {
+				kept = append(kept, m)
+			}
+		}
+		removed := len(s.zsets[cmd[1]]) - len(kept)
+		s.zsets[cmd[1]] = kept
+		return fmt.Sprintf(":%d\r\n", removed)
+	case "ZRANGEBYSCORE":
+		min, _ := strconv.Atoi(cmd[2])
+		max, _ := strconv.Atoi(cmd[3])
+		var members []string
+		for _, m := range s.zsets[cmd[1]] {
+			if m.score >= min && m.score <= max {
+				members = append(members, m.member)
+			}
+		}
+		return fakeRedisArray(members)
+	case "XADD":
+		args := cmd[2:]
+		maxLen := 0
+		if strings.ToUpper(args[0]) == "MAXLEN" {
+			maxLen, _ = strconv.Atoi(args[2])
+			args = args[3:]
+		}
+		s.nextID++
+		id := fmt.Sprintf("%d-0", s.nextID)
+		stream := append(s.streams[cmd[1]], append([]string{id}, args[1:]...))
+		if maxLen > 0 && len(stream) > maxLen {
+			stream = stream[len(stream)-maxLen:]
+		}
+		s.streams[cmd[1]] = stream
+		return fakeRedisBulk(id)
+	}
+
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd[0])
+}
+
+// entries returns the field values of the entries of a stream
+func (s *fakeRedisServer) entries(key string) []map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var entries []map[string]string
+	for _, entry := range s.streams[key] {
+		fields := make(map[string]string)
+		for i := 1; i+1 < len(entry); i += 2 {
+			fields[entry[i]] = entry[i+1]
+		}
+		entries = append(entries, fields)
+	}
+	return entries
+}
+
+func TestRedisClient_Replies(t *testing.T) {
+	server := newFakeRedisServer(t, "")
+	client, err := newRedisClient(server.addr(), "", 0, time.Second)
+	require.Nil(t, err)
+	defer client.Close()
+
+	reply, err := client.do("PING")
+	require.Nil(t, err)
+	assert.Equal(t, "PONG", reply)
+
+	reply, err = client.do("HSET", "h", "a", "1", "b", "")
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), reply)
+
+	reply, err = client.do("ZRANGEBYSCORE", "missing", "0", "1")
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{}, reply)
+
+	_, err = client.do("NOPE")
+	assert.Equal(t, redisError("ERR unknown command 'NOPE'"), err)
+
+	replies, err := client.pipeline([][]string{{"MULTI"}, {"HSET", "h", "a", "2"}, {"EXEC"}})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"OK", "QUEUED", []interface{}{int64(1)}}, replies)
+}
+
+func TestRedisClient_Auth(t *testing.T) {
+	server := newFakeRedisServer(t, "secret")
+
+	_, err := newRedisClient(server.addr(), "wrong", 0, time.Second)
+	assert.NotNil(t, err)
+
+	client, err := newRedisClient(server.addr(), "secret", 1, time.Second)
+	require.Nil(t, err)
+	defer client.Close()
+
+	_, err = client.do("PING")
+	assert.Nil(t, err)
+}
+
+func TestRedisClient_Reconnect(t *testing.T) {
+	server := newFakeRedisServer(t, "secret")
+	client, err := newRedisClient(server.addr(), "secret", 0, time.Second)
+	require.Nil(t, err)
+	defer client.Close()
+
+	// break the connection, the next command redials and authenticates again
+	client.conn.Close()
+	_, err = client.do("PING")
+	assert.NotNil(t, err)
+
+	_, err = client.do("PING")
+	assert.Nil(t, err)
+}
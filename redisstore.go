@@ -0,0 +1,280 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// defaultRedisKeyPrefix prefixes the keys of the redis store and log if no key prefix is set
+const defaultRedisKeyPrefix = "quickfix:"
+
+// redisTimeout bounds connecting to and each round trip with the redis server
+const redisTimeout = 5 * time.Second
+
+type redisStoreFactory struct {
+	settings *Settings
+}
+
+type redisStore struct {
+	sessionID   SessionID
+	cache       *memoryStore
+	client      *redisClient
+	sessionKey  string
+	messagesKey string
+}
+
+// NewRedisStoreFactory returns a redis-based implementation of MessageStoreFactory. The seqnums and creation time of
+// a session are kept in a hash and its messages in a sorted set scored by seqnum, so that stateless processes can
+// share session state.
+func NewRedisStoreFactory(settings *Settings) MessageStoreFactory {
+	return redisStoreFactory{settings: settings}
+}
+
+// Create creates a new RedisStore implementation of the MessageStore interface
+func (f redisStoreFactory) Create(sessionID SessionID) (msgStore MessageStore, err error) {
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %v", sessionID)
+	}
+
+	client, keyPrefix, err := newRedisClientFromSettings(sessionSettings,
+		config.RedisStoreAddress, config.RedisStorePassword, config.RedisStoreDB, config.RedisStoreKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newRedisStore(sessionID, client, keyPrefix)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// newRedisClientFromSettings connects to the redis server configured by the address, password, db and key prefix
+// settings
+func newRedisClientFromSettings(settings *SessionSettings, addressKey, passwordKey, dbKey, keyPrefixKey string) (client *redisClient, keyPrefix string, err error) {
+	address, err := settings.Setting(addressKey)
+	if err != nil {
+		return
+	}
+
+	var password string
+	if settings.HasSetting(passwordKey) {
+		if password, err = settings.Setting(passwordKey); err != nil {
+			return
+		}
+	}
+
+	var db int
+	if settings.HasSetting(dbKey) {
+		if db, err = settings.IntSetting(dbKey); err != nil {
+			return
+		}
+	}
+
+	keyPrefix = defaultRedisKeyPrefix
+	if settings.HasSetting(keyPrefixKey) {
+		if keyPrefix, err = settings.Setting(keyPrefixKey); err != nil {
+			return
+		}
+	}
+
+	client, err = newRedisClient(address, password, db, redisTimeout)
+	return
+}
+
+func newRedisStore(sessionID SessionID, client *redisClient, keyPrefix string) (*redisStore, error) {
+	sessionPrefix := keyPrefix + sessionIDFilenamePrefix(sessionID)
+	store := &redisStore{
+		sessionID:   sessionID,
+		cache:       &memoryStore{},
+		client:      client,
+		sessionKey:  sessionPrefix + ":session",
+		messagesKey: sessionPrefix + ":messages",
+	}
+
+	if err := store.cache.Reset(); err != nil {
+		return nil, errors.Wrap(err, "cache reset")
+	}
+
+	if err := store.populateCache(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// exec runs the commands in a MULTI/EXEC transaction
+func (store *redisStore) exec(cmds ...[]string) error {
+	tx := append([][]string{{"MULTI"}}, cmds...)
+	tx = append(tx, []string{"EXEC"})
+
+	replies, err := store.client.pipeline(tx)
+	if err != nil {
+		return err
+	}
+	if err := redisReplyError(replies); err != nil {
+		return err
+	}
+	if replies[len(replies)-1] == nil {
+		return errors.New("redis: transaction aborted")
+	}
+	return nil
+}
+
+// Reset deletes the store records and sets the seqnums back to 1
+func (store *redisStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	creationTime, err := store.cache.CreationTime().MarshalText()
+	if err != nil {
+		return err
+	}
+
+	return store.exec(
+		[]string{"DEL", store.messagesKey},
+		[]string{"HSET", store.sessionKey,
+			"creation_time", string(creationTime),
+			"incoming_seqnum", strconv.Itoa(store.cache.NextTargetMsgSeqNum()),
+			"outgoing_seqnum", strconv.Itoa(store.cache.NextSenderMsgSeqNum()),
+		},
+	)
+}
+
+// Refresh reloads the store from the database
+func (store *redisStore) Refresh() error {
+	if err := store.cache.Reset(); err != nil {
+		return err
+	}
+	return store.populateCache()
+}
+
+func (store *redisStore) populateCache() error {
+	reply, err := store.client.do("HGETALL", store.sessionKey)
+	if err != nil {
+		return errors.Wrap(err, "hgetall")
+	}
+
+	fields, _ := reply.([]interface{})
+	values := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		values[redisString(fields[i])] = redisString(fields[i+1])
+	}
+
+	creationTime, ok := values["creation_time"]
+	if !ok {
+		// session record not found, create it
+		return store.Reset()
+	}
+
+	if err := store.cache.creationTime.UnmarshalText([]byte(creationTime)); err != nil {
+		return errors.Wrap(err, "creation_time")
+	}
+
+	if incoming, err := strconv.Atoi(values["incoming_seqnum"]); err == nil {
+		if err := store.cache.SetNextTargetMsgSeqNum(incoming); err != nil {
+			return errors.Wrap(err, "cache set next target")
+		}
+	}
+	if outgoing, err := strconv.Atoi(values["outgoing_seqnum"]); err == nil {
+		if err := store.cache.SetNextSenderMsgSeqNum(outgoing); err != nil {
+			return errors.Wrap(err, "cache set next sender")
+		}
+	}
+
+	return nil
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent
+func (store *redisStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received
+func (store *redisStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent
+func (store *redisStore) SetNextSenderMsgSeqNum(next int) error {
+	if _, err := store.client.do("HSET", store.sessionKey, "outgoing_seqnum", strconv.Itoa(next)); err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received
+func (store *redisStore) SetNextTargetMsgSeqNum(next int) error {
+	if _, err := store.client.do("HSET", store.sessionKey, "incoming_seqnum", strconv.Itoa(next)); err != nil {
+		return err
+	}
+	return store.cache.SetNextTargetMsgSeqNum(next)
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent
+func (store *redisStore) IncrNextSenderMsgSeqNum() error {
+	return store.SetNextSenderMsgSeqNum(store.cache.NextSenderMsgSeqNum() + 1)
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received
+func (store *redisStore) IncrNextTargetMsgSeqNum() error {
+	return store.SetNextTargetMsgSeqNum(store.cache.NextTargetMsgSeqNum() + 1)
+}
+
+// CreationTime returns the creation time of the store
+func (store *redisStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime sets the creation time of the store
+func (store *redisStore) SetCreationTime(t time.Time) error {
+	creationTime, err := t.MarshalText()
+	if err != nil {
+		return err
+	}
+	if _, err := store.client.do("HSET", store.sessionKey, "creation_time", string(creationTime)); err != nil {
+		return err
+	}
+	store.cache.creationTime = t
+	return nil
+}
+
+// SaveMessage adds msg to the sorted set, replacing a message saved before with the same seqnum. Members are
+// prefixed with the seqnum to keep equal messages distinct.
+func (store *redisStore) SaveMessage(seqNum int, msg []byte) error {
+	score := strconv.Itoa(seqNum)
+	return store.exec(
+		[]string{"ZREMRANGEBYSCORE", store.messagesKey, score, score},
+		[]string{"ZADD", store.messagesKey, score, score + ":" + string(msg)},
+	)
+}
+
+func (store *redisStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	reply, err := store.client.do("ZRANGEBYSCORE", store.messagesKey, strconv.Itoa(beginSeqNum), strconv.Itoa(endSeqNum))
+	if err != nil {
+		return nil, errors.Wrap(err, "zrangebyscore")
+	}
+
+	members, _ := reply.([]interface{})
+	var msgs [][]byte
+	for _, member := range members {
+		b, _ := member.([]byte)
+		if sep := bytes.IndexByte(b, ':'); sep >= 0 {
+			msgs = append(msgs, b[sep+1:])
+		}
+	}
+	return msgs, nil
+}
+
+// Close closes the store's connection
+func (store *redisStore) Close() error {
+	return store.client.Close()
+}
@@ -0,0 +1,76 @@
+package quickfix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// RedisStoreTestSuite runs all tests in the MessageStoreTestSuite against the RedisStore implementation
+type RedisStoreTestSuite struct {
+	MessageStoreTestSuite
+	server   *fakeRedisServer
+	settings *Settings
+}
+
+func (suite *RedisStoreTestSuite) SetupTest() {
+	suite.server = newFakeRedisServer(suite.T(), "secret")
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	// create settings
+	var err error
+	suite.settings, err = ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+RedisStoreAddress=%s
+RedisStorePassword=secret
+RedisStoreKeyPrefix=test:
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.server.addr(), sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	// create store
+	suite.msgStore, err = NewRedisStoreFactory(suite.settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *RedisStoreTestSuite) TearDownTest() {
+	suite.msgStore.Close()
+}
+
+func (suite *RedisStoreTestSuite) TestSharedState() {
+	t := suite.T()
+	require.Nil(t, suite.msgStore.SaveMessage(1, []byte("hello")))
+	require.Nil(t, suite.msgStore.SaveMessage(2, []byte("hello")))
+	require.Nil(t, suite.msgStore.SaveMessage(1, []byte("HELLO")))
+	require.Nil(t, suite.msgStore.SetNextSenderMsgSeqNum(3))
+	require.Nil(t, suite.msgStore.IncrNextTargetMsgSeqNum())
+
+	// a store created by another process shares the state of the session
+	other, err := NewRedisStoreFactory(suite.settings).Create(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+	require.Nil(t, err)
+	defer other.Close()
+
+	assert.Equal(t, 3, other.NextSenderMsgSeqNum())
+	assert.Equal(t, 2, other.NextTargetMsgSeqNum())
+	assert.True(t, suite.msgStore.CreationTime().Equal(other.CreationTime()))
+
+	msgs, err := other.GetMessages(1, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("HELLO"), []byte("hello")}, msgs)
+
+	suite.server.mutex.Lock()
+	defer suite.server.mutex.Unlock()
+	assert.Contains(t, suite.server.hashes, "test:FIX.4.4-SENDER-TARGET:session")
+	assert.Len(t, suite.server.zsets["test:FIX.4.4-SENDER-TARGET:messages"], 2)
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisStoreTestSuite))
+}
@@ -1,6 +1,7 @@
 package quickfix
 
 import (
+	"context"
 	"errors"
 	"sync"
 )
@@ -9,6 +10,8 @@ var sessionsLock sync.RWMutex
 var sessions = make(map[SessionID]*session)
 var errDuplicateSessionID = errors.New("Duplicate SessionID")
 var errUnknownSession = errors.New("Unknown session")
+var errSessionNotRunning = errors.New("Session not running")
+var errMessageNotSent = errors.New("Message persisted but not sent, session not logged on")
 
 //Messagable is a Message or something that can be converted to a Message
 type Messagable interface {
@@ -50,6 +53,46 @@ func SendToTarget(m Messagable, sessionID SessionID) error {
 	return session.queueForSend(msg)
 }
 
+//SendToTargetSync sends a message based on the sessionID like SendToTarget, but blocks until the message has been
+//sequenced, persisted and written to the connection, or ctx is done. The assigned MsgSeqNum is returned with any error
+//from ToApp, the MessageStore or the write to the connection. The call does not wait for a logon: if the session is not
+//logged on when its event loop picks up the message, or disconnects before the message is written, the message remains
+//persisted for resend and errMessageNotSent is returned with its MsgSeqNum.
+func SendToTargetSync(ctx context.Context, m Messagable, sessionID SessionID) (seqNum int, err error) {
+	msg := m.ToMessage()
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return 0, errUnknownSession
+	}
+
+	session.runMutex.Lock()
+	done := session.runDone
+	session.runMutex.Unlock()
+
+	if done == nil {
+		return 0, errSessionNotRunning
+	}
+
+	sent := make(chan error, 1)
+	if err = session.queueForSendNotify(msg, sent); err != nil {
+		return 0, err
+	}
+
+	if seqNum, err = msg.Header.GetInt(tagMsgSeqNum); err != nil {
+		return 0, err
+	}
+
+	select {
+	case err = <-sent:
+	case <-done:
+		err = errSessionNotRunning
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return seqNum, err
+}
+
 //UnregisterSession removes a session from the set of known sessions
 func UnregisterSession(sessionID SessionID) error {
 	sessionsLock.Lock()
@@ -0,0 +1,143 @@
+package quickfix
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SendToTargetSyncSuite struct {
+	SessionSuiteRig
+	writeErr error
+}
+
+func TestSendToTargetSyncSuite(t *testing.T) {
+	suite.Run(t, new(SendToTargetSyncSuite))
+}
+
+func (s *SendToTargetSyncSuite) SetupTest() {
+	s.Init()
+	s.session.messageEvent = make(chan bool, 1)
+	s.session.runDone = make(chan struct{})
+	s.session.State = inSession{}
+	s.writeErr = nil
+	s.Require().Nil(registerSession(s.session))
+
+	msgOut := make(chan fixOut)
+	s.session.messageOut = msgOut
+	go writeLoop(s, msgOut, nullLog{})
+}
+
+func (s *SendToTargetSyncSuite) TearDownTest() {
+	_ = UnregisterSession(s.session.sessionID)
+	if s.session.messageOut != nil {
+		close(s.session.messageOut)
+	}
+}
+
+//Write is the connection of writeLoop, messages are written to the receiver unless writeErr is set
+func (s *SendToTargetSyncSuite) Write(msg []byte) (int, error) {
+	if s.writeErr != nil {
+		return 0, s.writeErr
+	}
+
+	s.Receiver.sendChannel <- fixOut{bytes: msg}
+	return len(msg), nil
+}
+
+//sendAppMessages processes one message event, like the session event loop
+func (s *SendToTargetSyncSuite) sendAppMessages() {
+	go func() {
+		<-s.session.messageEvent
+		s.session.SendAppMessages(s.session)
+	}()
+}
+
+func (s *SendToTargetSyncSuite) TestSent() {
+	s.MockApp.On("ToApp").Return(nil)
+	s.sendAppMessages()
+
+	seqNum, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), s.session.sessionID)
+	s.Nil(err)
+	s.Equal(1, seqNum)
+	s.LastToAppMessageSent()
+	s.NextSenderMsgSeqNum(2)
+}
+
+func (s *SendToTargetSyncSuite) TestWriteError() {
+	s.writeErr = errors.New("connection reset")
+	s.MockApp.On("ToApp").Return(nil)
+	s.sendAppMessages()
+
+	seqNum, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), s.session.sessionID)
+	s.Equal(s.writeErr, err)
+	s.Equal(1, seqNum)
+	s.NoMessageSent()
+}
+
+func (s *SendToTargetSyncSuite) TestDisconnected() {
+	s.MockApp.On("ToApp").Return(nil)
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), s.session.sessionID)
+		sent <- err
+	}()
+	<-s.session.messageEvent
+
+	s.session.onDisconnect()
+	s.Equal(errMessageNotSent, <-sent)
+	s.NoMessageSent()
+	s.Len(s.session.toSend, 1, "the message should remain queued")
+}
+
+func (s *SendToTargetSyncSuite) TestToAppError() {
+	s.MockApp.On("ToApp").Return(ErrDoNotSend)
+
+	_, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), s.session.sessionID)
+	s.Equal(ErrDoNotSend, err)
+	s.NoMessageQueued()
+	s.NextSenderMsgSeqNum(1)
+}
+
+func (s *SendToTargetSyncSuite) TestNotLoggedOn() {
+	s.session.State = latentState{}
+	s.MockApp.On("ToApp").Return(nil)
+	s.sendAppMessages()
+
+	//fails without waiting for a logon or the context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	seqNum, err := SendToTargetSync(ctx, s.NewOrderSingle(), s.session.sessionID)
+	s.Equal(errMessageNotSent, err)
+	s.Equal(1, seqNum)
+	s.NoMessageSent()
+	s.NextSenderMsgSeqNum(2)
+}
+
+func (s *SendToTargetSyncSuite) TestContextDone() {
+	s.MockApp.On("ToApp").Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	seqNum, err := SendToTargetSync(ctx, s.NewOrderSingle(), s.session.sessionID)
+	s.True(errors.Is(err, context.DeadlineExceeded))
+	s.Equal(1, seqNum)
+}
+
+func (s *SendToTargetSyncSuite) TestNotRunning() {
+	s.session.runDone = nil
+
+	_, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), s.session.sessionID)
+	s.Equal(errSessionNotRunning, err)
+	s.NextSenderMsgSeqNum(1)
+}
+
+func (s *SendToTargetSyncSuite) TestUnknownSession() {
+	_, err := SendToTargetSync(context.Background(), s.NewOrderSingle(), SessionID{BeginString: "FIX.4.2", TargetCompID: "unknown"})
+	s.Equal(errUnknownSession, err)
+}
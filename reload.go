@@ -0,0 +1,444 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+//SettingsDiff describes how sessions were affected when reloading settings.
+type SettingsDiff struct {
+	//Added sessions are configured in the new settings only
+	Added []SessionID
+
+	//Removed sessions are configured in the old settings only
+	Removed []SessionID
+
+	//Reloaded sessions had settings changed that were applied without interrupting the session
+	Reloaded []SessionID
+
+	//Restarted sessions had settings changed that required the session to be removed and added again
+	Restarted []SessionID
+}
+
+//SessionErrors maps sessions to the error encountered applying an operation to them.
+type SessionErrors map[SessionID]error
+
+func (e SessionErrors) Error() string {
+	errs := make([]string, 0, len(e))
+	for sessionID, err := range e {
+		errs = append(errs, fmt.Sprintf("%v: %v", sessionID, err))
+	}
+	sort.Strings(errs)
+
+	return strings.Join(errs, "; ")
+}
+
+//liveSettings can be changed without restarting the session
+var liveSettings = map[string]bool{
+	config.StartTime:                true,
+	config.EndTime:                  true,
+	config.StartDay:                 true,
+	config.EndDay:                   true,
+	config.TimeZone:                 true,
+	config.HeartBtInt:               true,
+	config.HeartBtIntOverride:       true,
+	config.MaxLatency:               true,
+	config.CheckLatency:             true,
+	config.ValidateFieldsOutOfOrder: true,
+	config.RejectInvalidMessage:     true,
+	config.FileLogPath:              true,
+	config.FileStorePath:            true,
+}
+
+//acceptorSettings apply to all sessions of an Acceptor and require restarting the Acceptor
+var acceptorSettings = []string{
+	config.SocketAcceptHost,
+	config.UseTCPProxy,
+	config.DynamicSessions,
+	config.DynamicQualifier,
+	config.SocketUseSSL,
+	config.SocketPrivateKeyFile,
+	config.SocketCertificateFile,
+	config.SocketCAFile,
+	config.SocketInsecureSkipVerify,
+	config.SocketServerName,
+	config.SocketMinimumTLSVersion,
+}
+
+//changedSettings returns the settings that differ between old and new
+func changedSettings(old, new *SessionSettings) (changed []string) {
+	for setting, value := range old.settings {
+		if newValue, ok := new.settings[setting]; !ok || newValue != value {
+			changed = append(changed, setting)
+		}
+	}
+
+	for setting := range new.settings {
+		if _, ok := old.settings[setting]; !ok {
+			changed = append(changed, setting)
+		}
+	}
+
+	sort.Strings(changed)
+	return
+}
+
+//checkGlobalSettings returns an error if any of the given global settings differ between old and new
+func checkGlobalSettings(old, new *Settings, settings []string) error {
+	for _, setting := range settings {
+		oldValue, oldOk := old.GlobalSettings().settings[setting]
+		newValue, newOk := new.GlobalSettings().settings[setting]
+		if oldOk != newOk || oldValue != newValue {
+			return fmt.Errorf("%v cannot be reloaded, restart is required", setting)
+		}
+	}
+
+	return nil
+}
+
+//diffSettings compares the sessions configured in old and new
+func diffSettings(old, new *Settings) (diff SettingsDiff) {
+	oldSessions := old.SessionSettings()
+	newSessions := new.SessionSettings()
+
+	for sessionID, oldSettings := range oldSessions {
+		newSettings, ok := newSessions[sessionID]
+		if !ok {
+			diff.Removed = append(diff.Removed, sessionID)
+			continue
+		}
+
+		changed := changedSettings(oldSettings, newSettings)
+		if len(changed) == 0 {
+			continue
+		}
+
+		restart := false
+		for _, setting := range changed {
+			if !liveSettings[setting] {
+				restart = true
+				break
+			}
+		}
+
+		if restart {
+			diff.Restarted = append(diff.Restarted, sessionID)
+		} else {
+			diff.Reloaded = append(diff.Reloaded, sessionID)
+		}
+	}
+
+	for sessionID := range newSessions {
+		if _, ok := oldSessions[sessionID]; !ok {
+			diff.Added = append(diff.Added, sessionID)
+		}
+	}
+
+	for _, sessionIDs := range [][]SessionID{diff.Added, diff.Removed, diff.Reloaded, diff.Restarted} {
+		sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+	}
+
+	return
+}
+
+//reloadSettings applies new settings to the sessions configured in current. Removed sessions, and sessions requiring a
+//restart, are removed first. Reloaded sessions are then updated in place and finally added and restarted sessions
+//are added. Errors are collected per session and do not stop other sessions from being updated.
+func reloadSettings(current, new *Settings,
+	remove func(SessionID) error,
+	add func(SessionID, *SessionSettings) error,
+	reload func(sessionID SessionID, old, new *SessionSettings) error,
+) (diff SettingsDiff, err error) {
+	diff = diffSettings(current, new)
+	oldSessions := current.SessionSettings()
+	errs := make(SessionErrors)
+
+	for _, sessionIDs := range [][]SessionID{diff.Removed, diff.Restarted} {
+		for _, sessionID := range sessionIDs {
+			if err := remove(sessionID); err != nil {
+				errs[sessionID] = err
+			}
+		}
+	}
+
+	current.setGlobalSettings(new.GlobalSettings())
+
+	for _, sessionID := range diff.Reloaded {
+		current.setSession(sessionID, new.sessionSettingsFor(sessionID))
+		if err := reload(sessionID, oldSessions[sessionID], current.SessionSettings()[sessionID]); err != nil {
+			errs[sessionID] = err
+		}
+	}
+
+	for _, sessionIDs := range [][]SessionID{diff.Restarted, diff.Added} {
+		for _, sessionID := range sessionIDs {
+			if _, failed := errs[sessionID]; failed {
+				continue
+			}
+
+			if err := add(sessionID, new.sessionSettingsFor(sessionID)); err != nil {
+				errs[sessionID] = err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		err = errs
+	}
+
+	return
+}
+
+//reloadSession applies live settings to a session. A new log or message store is created if its path changed.
+func (f sessionFactory) reloadSession(s *session, old, new *SessionSettings, storeFactory MessageStoreFactory, logFactory LogFactory) (err error) {
+	req := reloadReq{config: &session{sessionID: s.sessionID}}
+	if err = f.configureSession(req.config, new); err != nil {
+		return
+	}
+
+	for _, setting := range changedSettings(old, new) {
+		switch setting {
+		case config.FileLogPath:
+			if req.log, err = logFactory.CreateSessionLog(s.sessionID); err != nil {
+				return
+			}
+
+		case config.FileStorePath:
+			if req.store, err = storeFactory.Create(s.sessionID); err != nil {
+				return
+			}
+		}
+	}
+
+	rep := make(chan error, 1)
+	req.rep = rep
+	for {
+		if s.reloadIfNotRunning(req) {
+			return <-rep
+		}
+
+		//the session may stop before the request is received, it is then reloaded directly
+		if err = s.sendAdmin(req); err != errSessionNotRunning {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	return <-rep
+}
+
+//reloadIfNotRunning applies req directly if the session event loop is not running. runMutex is held while req is
+//applied, so that a run starting concurrently waits for it rather than racing it.
+func (s *session) reloadIfNotRunning(req reloadReq) bool {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.runDone != nil {
+		return false
+	}
+
+	s.reload(req)
+	return true
+}
+
+type reloadReq struct {
+	//config holds the reloaded session configuration
+	config *session
+	log    Log
+	store  MessageStore
+	rep    chan<- error
+}
+
+type heartBtIntSettings struct {
+	heartBtInt         time.Duration
+	heartBtIntOverride bool
+}
+
+func (s *session) reload(req reloadReq) {
+	s.SessionTime = req.config.SessionTime
+	s.MaxLatency = req.config.MaxLatency
+	s.SkipCheckLatency = req.config.SkipCheckLatency
+	s.Validator = req.config.Validator
+
+	heartBt := heartBtIntSettings{
+		heartBtInt:         req.config.HeartBtInt,
+		heartBtIntOverride: req.config.HeartBtIntOverride,
+	}
+	if s.State != nil && s.IsConnected() {
+		s.pendingHeartBtInt = &heartBt
+	} else {
+		s.applyHeartBtInt(heartBt)
+	}
+
+	if req.log != nil {
+		if log, ok := s.log.(*swappableLog); ok {
+			closeLog(log.swap(req.log))
+		} else {
+			closeLog(s.log)
+			s.log = req.log
+		}
+	}
+
+	if req.store != nil {
+		s.sendMutex.Lock()
+		err := s.swapStore(req.store)
+		s.sendMutex.Unlock()
+
+		if err != nil {
+			s.logError(err)
+			if req.rep != nil {
+				req.rep <- err
+			}
+			return
+		}
+	}
+
+	s.logEvent(LogLevelInfo, LogCodeSettingsReloaded, "Settings reloaded")
+	if req.rep != nil {
+		req.rep <- nil
+	}
+}
+
+//applyHeartBtInt applies heartbeat settings, unless the session is configured to take the interval from the
+//counterparty Logon and has not been overridden
+func (s *session) applyHeartBtInt(heartBt heartBtIntSettings) {
+	s.HeartBtIntOverride = heartBt.heartBtIntOverride
+	if s.InitiateLogon || s.HeartBtIntOverride {
+		s.HeartBtInt = heartBt.heartBtInt
+	}
+	s.pendingHeartBtInt = nil
+}
+
+//swapStore replaces the message store, carrying over the sequence numbers, the messages persisted for resend and the
+//creation time so the session continues uninterrupted. Anything held by the new store is reset. The creation time is
+//that of the reset if the new store is not a CreationTimeSetter.
+func (s *session) swapStore(store MessageStore) error {
+	if err := s.copyStore(store); err != nil {
+		_ = store.Close()
+		return err
+	}
+
+	if err := s.store.Close(); err != nil {
+		s.logError(err)
+	}
+	s.store = store
+	return nil
+}
+
+func (s *session) copyStore(store MessageStore) error {
+	if err := store.Reset(); err != nil {
+		return err
+	}
+
+	if err := s.copyMessages(store); err != nil {
+		return fmt.Errorf("copying messages: %v", err)
+	}
+
+	if err := store.SetNextSenderMsgSeqNum(s.store.NextSenderMsgSeqNum()); err != nil {
+		return err
+	}
+
+	if err := store.SetNextTargetMsgSeqNum(s.store.NextTargetMsgSeqNum()); err != nil {
+		return err
+	}
+
+	if setter, ok := store.(CreationTimeSetter); ok {
+		return setter.SetCreationTime(s.store.CreationTime())
+	}
+	return nil
+}
+
+//copyMessages saves the messages persisted to the session store to store
+func (s *session) copyMessages(store MessageStore) error {
+	endSeqNum := s.store.NextSenderMsgSeqNum() - 1
+	if endSeqNum < 1 {
+		return nil
+	}
+
+	//msg is only valid during the call of fn
+	if iterator, ok := s.store.(MessageIterator); ok {
+		return iterator.IterateMessages(1, endSeqNum, func(seqNum int, msg []byte) error {
+			return store.SaveMessage(seqNum, append([]byte(nil), msg...))
+		})
+	}
+
+	msgs, err := s.store.GetMessages(1, endSeqNum)
+	if err != nil {
+		return err
+	}
+
+	for _, msgBytes := range msgs {
+		msg := NewMessage()
+		if err := ParseMessage(msg, bytes.NewBuffer(msgBytes)); err != nil {
+			return err
+		}
+
+		seqNum, err := msg.Header.GetInt(tagMsgSeqNum)
+		if err != nil {
+			return err
+		}
+
+		if err := store.SaveMessage(seqNum, msgBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//closeLog closes the log if it holds resources, e.g. files
+func closeLog(log Log) {
+	if closer, ok := log.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+//swappableLog allows the log of a session to be replaced while other goroutines, e.g. connection handlers, use it
+type swappableLog struct {
+	mutex sync.RWMutex
+	log   Log
+}
+
+func newSwappableLog(log Log) *swappableLog {
+	return &swappableLog{log: log}
+}
+
+//swap replaces the log, returning the previous one
+func (l *swappableLog) swap(log Log) (old Log) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	old, l.log = l.log, log
+	return
+}
+
+func (l *swappableLog) current() Log {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.log
+}
+
+func (l *swappableLog) OnIncoming(msg []byte) { l.current().OnIncoming(msg) }
+func (l *swappableLog) OnOutgoing(msg []byte) { l.current().OnOutgoing(msg) }
+func (l *swappableLog) OnEvent(msg string)    { l.current().OnEvent(msg) }
+func (l *swappableLog) OnEventf(format string, v ...interface{}) {
+	l.current().OnEventf(format, v...)
+}
+func (l *swappableLog) OnLogEvent(event LogEvent) { logEvent(l.current(), event) }
+
+func (l *swappableLog) Close() error {
+	if closer, ok := l.current().(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package quickfix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReloadSuite struct {
+	SessionSuiteRig
+}
+
+func TestReloadSuite(t *testing.T) {
+	suite.Run(t, new(ReloadSuite))
+}
+
+func (s *ReloadSuite) SetupTest() {
+	s.Init()
+}
+
+func (s *ReloadSuite) newSettings(sessions ...map[string]string) *Settings {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.BeginString, BeginStringFIX42)
+	settings.GlobalSettings().Set(config.SenderCompID, "reload")
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5006")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+
+	for _, values := range sessions {
+		sessionSettings := NewSessionSettings()
+		for setting, value := range values {
+			sessionSettings.Set(setting, value)
+		}
+
+		_, err := settings.AddSession(sessionSettings)
+		s.Require().Nil(err)
+	}
+
+	return settings
+}
+
+func (s *ReloadSuite) sessionID(targetCompID string) SessionID {
+	return SessionID{BeginString: BeginStringFIX42, SenderCompID: "reload", TargetCompID: targetCompID}
+}
+
+func (s *ReloadSuite) TestDiffSettings() {
+	old := s.newSettings(
+		map[string]string{config.TargetCompID: "unchanged"},
+		map[string]string{config.TargetCompID: "removed"},
+		map[string]string{config.TargetCompID: "live", config.MaxLatency: "120"},
+		map[string]string{config.TargetCompID: "restart", config.ResetOnLogon: "N"},
+	)
+	new := s.newSettings(
+		map[string]string{config.TargetCompID: "unchanged"},
+		map[string]string{config.TargetCompID: "live", config.MaxLatency: "60", config.StartTime: "00:00:00", config.EndTime: "00:00:00"},
+		map[string]string{config.TargetCompID: "restart", config.ResetOnLogon: "Y", config.MaxLatency: "60"},
+		map[string]string{config.TargetCompID: "added"},
+	)
+
+	diff := diffSettings(old, new)
+	s.Equal([]SessionID{s.sessionID("added")}, diff.Added)
+	s.Equal([]SessionID{s.sessionID("removed")}, diff.Removed)
+	s.Equal([]SessionID{s.sessionID("live")}, diff.Reloaded)
+	s.Equal([]SessionID{s.sessionID("restart")}, diff.Restarted)
+}
+
+func (s *ReloadSuite) TestDiffSettingsGlobalChange() {
+	old := s.newSettings(map[string]string{config.TargetCompID: "global"})
+	new := s.newSettings(map[string]string{config.TargetCompID: "global"})
+	new.GlobalSettings().Set(config.SocketAcceptPort, "5007")
+
+	diff := diffSettings(old, new)
+	s.Equal([]SessionID{s.sessionID("global")}, diff.Restarted)
+}
+
+func (s *ReloadSuite) TestReloadWhileConnected() {
+	s.session.State = inSession{}
+	s.session.InitiateLogon = true
+	s.session.HeartBtInt = 30 * time.Second
+
+	settings := NewSessionSettings()
+	settings.Set(config.HeartBtInt, "10")
+	settings.Set(config.MaxLatency, "5")
+	settings.Set(config.SocketConnectHost, "localhost")
+	settings.Set(config.SocketConnectPort, "5001")
+
+	req := reloadReq{config: &session{sessionID: s.session.sessionID}}
+	s.Require().Nil(sessionFactory{BuildInitiators: true}.configureSession(req.config, settings))
+
+	s.session.reload(req)
+	s.Equal(5*time.Second, s.session.MaxLatency)
+	s.Equal(30*time.Second, s.session.HeartBtInt, "HeartBtInt should not change until the next logon")
+
+	s.session.onDisconnect()
+	s.Equal(10*time.Second, s.session.HeartBtInt)
+	s.Nil(s.session.pendingHeartBtInt)
+}
+
+func (s *ReloadSuite) TestReloadSwapStore() {
+	creationTime := time.Date(2021, time.June, 1, 2, 3, 4, 0, time.UTC)
+	for _, iterating := range []bool{true, false} {
+		s.SetupTest()
+		s.session.State = latentState{}
+		s.Require().Nil(s.MockStore.SetCreationTime(creationTime))
+		var saved [][]byte
+		for seqNum := 1; seqNum <= 2; seqNum++ {
+			msg := s.NewOrderSingle()
+			msg.Header.SetField(tagMsgSeqNum, FIXInt(seqNum))
+			saved = append(saved, msg.build())
+			s.Require().Nil(s.session.store.SaveMessage(seqNum, saved[seqNum-1]))
+		}
+		s.Require().Nil(s.session.store.SetNextSenderMsgSeqNum(10))
+		s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(20))
+		if !iterating {
+			s.session.store = getMessagesStore{&s.MockStore}
+		}
+
+		store := new(memoryStore)
+		s.Require().Nil(store.Reset())
+		s.Require().Nil(store.SaveMessage(3, []byte("stale")))
+
+		req := reloadReq{config: &session{sessionID: s.session.sessionID}, store: store}
+		s.session.reload(req)
+
+		s.Equal(store, s.session.store)
+		s.NextSenderMsgSeqNum(10)
+		s.NextTargetMsgSeqNum(20)
+		s.Equal(creationTime, store.CreationTime())
+
+		msgs, err := store.GetMessages(1, 9)
+		s.Require().Nil(err)
+		s.Equal(saved, msgs, "the messages should be copied for resend")
+	}
+}
+
+//blockingResetStore blocks Reset until reset is closed
+type blockingResetStore struct {
+	memoryStore
+	resetting chan struct{}
+	reset     chan struct{}
+}
+
+func (store *blockingResetStore) Reset() error {
+	close(store.resetting)
+	<-store.reset
+	return store.memoryStore.Reset()
+}
+
+func (s *ReloadSuite) TestReloadNotRunning() {
+	s.session.State = latentState{}
+	store := &blockingResetStore{resetting: make(chan struct{}), reset: make(chan struct{})}
+
+	rep := make(chan error, 1)
+	go func() {
+		s.True(s.session.reloadIfNotRunning(reloadReq{config: &session{sessionID: s.session.sessionID}, store: store, rep: rep}))
+	}()
+	<-store.resetting
+
+	running := make(chan struct{})
+	go func() {
+		s.session.markRunning()
+		close(running)
+	}()
+
+	select {
+	case <-running:
+		s.Fail("the session should not start running during the reload")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(store.reset)
+	s.Nil(<-rep)
+	<-running
+	s.Equal(store, s.session.store)
+	s.False(s.session.reloadIfNotRunning(reloadReq{config: &session{sessionID: s.session.sessionID}}))
+}
+
+func (s *ReloadSuite) TestAcceptorReload() {
+	settings := s.newSettings(
+		map[string]string{config.TargetCompID: "acceptor_removed"},
+		map[string]string{config.TargetCompID: "acceptor_live", config.MaxLatency: "120"},
+		map[string]string{config.TargetCompID: "acceptor_restart", config.ResetOnLogon: "N"},
+	)
+
+	acceptor, err := NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	s.Require().Nil(err)
+	s.Require().Nil(acceptor.Start())
+	defer acceptor.Stop()
+
+	live, _ := lookupSession(s.sessionID("acceptor_live"))
+	restart, _ := lookupSession(s.sessionID("acceptor_restart"))
+
+	diff, err := acceptor.Reload(s.newSettings(
+		map[string]string{config.TargetCompID: "acceptor_live", config.MaxLatency: "60"},
+		map[string]string{config.TargetCompID: "acceptor_restart", config.ResetOnLogon: "Y"},
+		map[string]string{config.TargetCompID: "acceptor_added"},
+	))
+	s.Require().Nil(err)
+	s.Equal([]SessionID{s.sessionID("acceptor_added")}, diff.Added)
+	s.Equal([]SessionID{s.sessionID("acceptor_removed")}, diff.Removed)
+	s.Equal([]SessionID{s.sessionID("acceptor_live")}, diff.Reloaded)
+	s.Equal([]SessionID{s.sessionID("acceptor_restart")}, diff.Restarted)
+
+	_, ok := LookupSession(s.sessionID("acceptor_removed"))
+	s.False(ok)
+	_, ok = LookupSession(s.sessionID("acceptor_added"))
+	s.True(ok)
+
+	reloaded, _ := lookupSession(s.sessionID("acceptor_live"))
+	s.True(live == reloaded, "session should not be restarted")
+	s.Equal(60*time.Second, live.MaxLatency)
+
+	restarted, _ := lookupSession(s.sessionID("acceptor_restart"))
+	s.False(restart == restarted, "session should be restarted")
+	s.True(restarted.ResetOnLogon)
+
+	s.Len(settings.SessionSettings(), 3)
+	s.Equal("60", settings.SessionSettings()[s.sessionID("acceptor_live")].settings[config.MaxLatency])
+}
+
+func (s *ReloadSuite) TestAcceptorReloadAcceptorSetting() {
+	settings := s.newSettings(map[string]string{config.TargetCompID: "acceptor_setting"})
+	acceptor, err := NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	s.Require().Nil(err)
+
+	new := s.newSettings(map[string]string{config.TargetCompID: "acceptor_setting", config.MaxLatency: "60"})
+	new.GlobalSettings().Set(config.SocketAcceptHost, "127.0.0.1")
+
+	_, err = acceptor.Reload(new)
+	s.NotNil(err)
+	s.NotContains(settings.SessionSettings()[s.sessionID("acceptor_setting")].settings, config.MaxLatency)
+}
@@ -18,11 +18,11 @@ type session struct {
 	log       Log
 	sessionID SessionID
 
-	messageOut chan<- []byte
+	messageOut chan<- fixOut
 	messageIn  <-chan fixIn
 
 	//application messages are queued up for send here
-	toSend [][]byte
+	toSend []queuedMessage
 
 	//mutex for access to toSend
 	sendMutex sync.Mutex
@@ -30,6 +30,10 @@ type session struct {
 	sessionEvent chan internal.Event
 	messageEvent chan bool
 	application  Application
+
+	//optionally implemented by the application
+	eventListener SessionEventListener
+
 	Validator
 	stateMachine
 	stateTimer *internal.EventTimer
@@ -39,6 +43,20 @@ type session struct {
 	targetDefaultApplVerID string
 
 	admin chan interface{}
+
+	//signals an initiator to connect without waiting for the reconnect interval
+	connectNow chan struct{}
+
+	//set while stopping if the logout response is awaited without LogoutTimeout
+	awaitLogout bool
+
+	//heartbeat settings reloaded while connected, applied on disconnect to take effect from the next logon
+	pendingHeartBtInt *heartBtIntSettings
+
+	//runDone is closed when run returns, nil while the session is not running
+	runDone  chan struct{}
+	runMutex sync.Mutex
+
 	internal.SessionSettings
 	transportDataDictionary *datadictionary.DataDictionary
 	appDataDictionary       *datadictionary.DataDictionary
@@ -48,7 +66,7 @@ type session struct {
 }
 
 func (s *session) logError(err error) {
-	s.log.OnEvent(err.Error())
+	s.logEvent(LogLevelError, LogCodeError, err.Error(), LogAttr{"error", err})
 }
 
 //TargetDefaultApplicationVersionID returns the default application version ID for messages received by this version.
@@ -58,28 +76,137 @@ func (s *session) TargetDefaultApplicationVersionID() string {
 }
 
 type connect struct {
-	messageOut chan<- []byte
+	messageOut chan<- fixOut
 	messageIn  <-chan fixIn
 	err        chan<- error
 }
 
-func (s *session) connect(msgIn <-chan fixIn, msgOut chan<- []byte) error {
+func (s *session) connect(msgIn <-chan fixIn, msgOut chan<- fixOut) error {
 	rep := make(chan error)
-	s.admin <- connect{
+	if err := s.sendAdmin(connect{
 		messageOut: msgOut,
 		messageIn:  msgIn,
 		err:        rep,
+	}); err != nil {
+		return err
 	}
 
 	return <-rep
 }
 
-type stopReq struct{}
+type stopReq struct {
+	//awaitLogout waits for the logout response without LogoutTimeout, the caller bounds the wait
+	awaitLogout bool
+}
 
 func (s *session) stop() {
 	s.admin <- stopReq{}
 }
 
+//stopAndWait stops the session if it is running and waits for its event loop to exit
+func (s *session) stopAndWait() {
+	s.runMutex.Lock()
+	done := s.runDone
+	s.runMutex.Unlock()
+
+	if done == nil {
+		return
+	}
+
+	select {
+	case s.admin <- stopReq{}:
+	case <-done:
+	}
+	<-done
+}
+
+type statusReq struct{ rep chan<- SessionStatus }
+
+type logonReq struct{ rep chan<- error }
+
+type logoutReq struct {
+	reason string
+	rep    chan<- error
+}
+
+type disconnectReq struct{ rep chan<- error }
+
+type resetReq struct{ rep chan<- error }
+
+type refreshReq struct{ rep chan<- error }
+
+type setNextSenderMsgSeqNumReq struct {
+	next          int
+	sequenceReset bool
+	rep           chan<- error
+}
+
+type setNextTargetMsgSeqNumReq struct {
+	next          int
+	resendRequest bool
+	rep           chan<- error
+}
+
+//sendAdmin delivers msg to the session event loop, failing if the session is not running
+func (s *session) sendAdmin(msg interface{}) error {
+	s.runMutex.Lock()
+	done := s.runDone
+	s.runMutex.Unlock()
+
+	if done == nil {
+		return errSessionNotRunning
+	}
+
+	select {
+	case s.admin <- msg:
+		return nil
+	case <-done:
+		return errSessionNotRunning
+	}
+}
+
+func (s *session) status() SessionStatus {
+	s.sendMutex.Lock()
+	defer s.sendMutex.Unlock()
+
+	return SessionStatus{
+		SessionID:           s.sessionID,
+		State:               s.State.String(),
+		IsConnected:         s.IsConnected(),
+		IsLoggedOn:          s.IsLoggedOn(),
+		IsSessionTime:       s.IsSessionTime(),
+		NextSenderMsgSeqNum: s.store.NextSenderMsgSeqNum(),
+		NextTargetMsgSeqNum: s.store.NextTargetMsgSeqNum(),
+		CreationTime:        s.store.CreationTime(),
+	}
+}
+
+func (s *session) requestLogon() error {
+	switch {
+	case !s.InitiateLogon:
+		return errors.New("Only initiators can initiate logon")
+	case s.IsConnected():
+		return errors.New("Already connected")
+	case !s.IsSessionTime():
+		return errors.New("Not in session time")
+	}
+
+	select {
+	case s.connectNow <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (s *session) refresh() error {
+	s.sendMutex.Lock()
+	defer s.sendMutex.Unlock()
+
+	s.log.OnEvent("Refreshing session state from store")
+	return s.store.Refresh()
+}
+
 type waitChan <-chan interface{}
 
 type waitForInSessionReq struct{ rep chan<- waitChan }
@@ -205,8 +332,22 @@ func (s *session) resend(msg *Message) bool {
 	return s.application.ToApp(msg, s.sessionID) == nil
 }
 
+//queuedMessage is a message waiting in the send queue
+type queuedMessage struct {
+	bytes []byte
+
+	//if set, receives the outcome once the message is written to the connection or dropped from the queue
+	sent chan<- error
+}
+
 //queueForSend will validate, persist, and queue the message for send
 func (s *session) queueForSend(msg *Message) error {
+	return s.queueForSendNotify(msg, nil)
+}
+
+//queueForSendNotify queues the message for send like queueForSend, sending the outcome to sent once the message is
+//written to the connection, or dropped from the queue or disconnected before it is written
+func (s *session) queueForSendNotify(msg *Message, sent chan<- error) error {
 	s.sendMutex.Lock()
 	defer s.sendMutex.Unlock()
 
@@ -215,8 +356,9 @@ func (s *session) queueForSend(msg *Message) error {
 		return err
 	}
 
-	s.toSend = append(s.toSend, msgBytes)
+	s.toSend = append(s.toSend, queuedMessage{bytes: msgBytes, sent: sent})
 
+	//the event loop sends the queue, or drops it notifying sent with errMessageNotSent if the session is not logged on
 	select {
 	case s.messageEvent <- true:
 	default:
@@ -242,7 +384,7 @@ func (s *session) sendInReplyTo(msg *Message, inReplyTo *Message) error {
 		return err
 	}
 
-	s.toSend = append(s.toSend, msgBytes)
+	s.toSend = append(s.toSend, queuedMessage{bytes: msgBytes})
 	s.sendQueued()
 
 	return nil
@@ -271,7 +413,7 @@ func (s *session) dropAndSendInReplyTo(msg *Message, inReplyTo *Message) error {
 	}
 
 	s.dropQueued()
-	s.toSend = append(s.toSend, msgBytes)
+	s.toSend = append(s.toSend, queuedMessage{bytes: msgBytes})
 	s.sendQueued()
 
 	return nil
@@ -331,14 +473,23 @@ func (s *session) persist(seqNum int, msgBytes []byte) error {
 }
 
 func (s *session) sendQueued() {
-	for _, msgBytes := range s.toSend {
-		s.sendBytes(msgBytes)
+	for n, queued := range s.toSend {
+		s.sendBytes(queued.bytes, queued.sent)
+		s.toSend[n] = queuedMessage{}
 	}
 
-	s.dropQueued()
+	s.toSend = s.toSend[:0]
 }
 
 func (s *session) dropQueued() {
+	for n, queued := range s.toSend {
+		if queued.sent != nil {
+			queued.sent <- errMessageNotSent
+		}
+
+		s.toSend[n] = queuedMessage{}
+	}
+
 	s.toSend = s.toSend[:0]
 }
 
@@ -346,18 +497,38 @@ func (s *session) EnqueueBytesAndSend(msg []byte) {
 	s.sendMutex.Lock()
 	defer s.sendMutex.Unlock()
 
-	s.toSend = append(s.toSend, msg)
+	s.toSend = append(s.toSend, queuedMessage{bytes: msg})
 	s.sendQueued()
 }
 
-func (s *session) sendBytes(msg []byte) {
+//sendBytes hands msg to writeLoop, which sends the outcome of writing it to sent if set
+func (s *session) sendBytes(msg []byte, sent chan<- error) {
 	s.log.OnOutgoing(msg)
-	s.messageOut <- msg
+	s.messageOut <- fixOut{bytes: msg, sent: sent}
 	s.stateTimer.Reset(s.HeartBtInt)
 }
 
+//sendSequenceReset sends a SequenceReset in reset mode. The message is not persisted and does not consume a MsgSeqNum.
+func (s *session) sendSequenceReset(newSeqNo int) error {
+	sequenceReset := NewMessage()
+	sequenceReset.Header.SetBytes(tagMsgType, msgTypeSequenceReset)
+	s.fillDefaultHeader(sequenceReset, nil)
+	sequenceReset.Header.SetField(tagMsgSeqNum, FIXInt(newSeqNo))
+	sequenceReset.Body.SetField(tagNewSeqNo, FIXInt(newSeqNo))
+
+	s.application.ToAdmin(sequenceReset, s.sessionID)
+
+	s.EnqueueBytesAndSend(sequenceReset.build())
+	s.logEvent(LogLevelInfo, LogCodeSequenceResetSent, fmt.Sprintf("Sent SequenceReset TO: %v", newSeqNo), LogAttr{"new_seq_no", newSeqNo})
+
+	return nil
+}
+
 func (s *session) doTargetTooHigh(reject targetTooHigh) (nextState resendState, err error) {
-	s.log.OnEventf("MsgSeqNum too high, expecting %v but received %v", reject.ExpectedTarget, reject.ReceivedTarget)
+	s.logEvent(LogLevelWarn, LogCodeSequenceGap,
+		fmt.Sprintf("MsgSeqNum too high, expecting %v but received %v", reject.ExpectedTarget, reject.ReceivedTarget),
+		LogAttr{"expected_seq_num", reject.ExpectedTarget}, LogAttr{"received_seq_num", reject.ReceivedTarget})
+	s.notify(SequenceGapEvent{Expected: reject.ExpectedTarget, Received: reject.ReceivedTarget})
 	return s.sendResendRequest(reject.ExpectedTarget, reject.ReceivedTarget-1)
 }
 
@@ -389,7 +560,9 @@ func (s *session) sendResendRequest(beginSeq, endSeq int) (nextState resendState
 	if err = s.send(resend); err != nil {
 		return
 	}
-	s.log.OnEventf("Sent ResendRequest FROM: %v TO: %v", beginSeq, endSeqNo)
+	s.logEvent(LogLevelInfo, LogCodeResendRequestSent, fmt.Sprintf("Sent ResendRequest FROM: %v TO: %v", beginSeq, endSeqNo),
+		LogAttr{"begin_seq_no", beginSeq}, LogAttr{"end_seq_no", endSeqNo})
+	s.notify(ResendRequestSentEvent{BeginSeqNo: beginSeq, EndSeqNo: endSeqNo})
 
 	return
 }
@@ -475,7 +648,9 @@ func (s *session) initiateLogoutInReplyTo(reason string, inReplyTo *Message) (er
 		return
 	}
 	s.log.OnEvent("Inititated logout request")
-	time.AfterFunc(s.LogoutTimeout, func() { s.sessionEvent <- internal.LogoutTimeout })
+	if !s.awaitLogout {
+		time.AfterFunc(s.LogoutTimeout, func() { s.sessionEvent <- internal.LogoutTimeout })
+	}
 	return
 }
 
@@ -669,7 +844,7 @@ func (s *session) doReject(msg *Message, rej MessageRejectError) error {
 		reply.Body.SetField(tagRefSeqNum, seqNum)
 	}
 
-	s.log.OnEventf("Message Rejected: %v", rej.Error())
+	s.logEvent(LogLevelWarn, LogCodeMessageRejected, fmt.Sprintf("Message Rejected: %v", rej.Error()), LogAttr{"error", rej})
 	return s.sendInReplyTo(reply, msg)
 }
 
@@ -678,6 +853,13 @@ type fixIn struct {
 	receiveTime time.Time
 }
 
+type fixOut struct {
+	bytes []byte
+
+	//if set, receives the outcome of writing the message to the connection
+	sent chan<- error
+}
+
 func (s *session) returnToPool(msg *Message) {
 	s.messagePool.Put(msg)
 	if msg.rawMessage != nil {
@@ -687,19 +869,34 @@ func (s *session) returnToPool(msg *Message) {
 }
 
 func (s *session) onDisconnect() {
-	s.log.OnEvent("Disconnected")
+	s.logEvent(LogLevelInfo, LogCodeDisconnected, "Disconnected")
+	s.notify(DisconnectedEvent{})
 	if s.ResetOnDisconnect {
 		if err := s.dropAndReset(); err != nil {
 			s.logError(err)
 		}
 	}
 
+	//queued messages remain persisted for resend, but they are not written to this connection
+	s.sendMutex.Lock()
+	for n := range s.toSend {
+		if s.toSend[n].sent != nil {
+			s.toSend[n].sent <- errMessageNotSent
+			s.toSend[n].sent = nil
+		}
+	}
+	s.sendMutex.Unlock()
+
 	if s.messageOut != nil {
 		close(s.messageOut)
 		s.messageOut = nil
 	}
 
 	s.messageIn = nil
+
+	if s.pendingHeartBtInt != nil {
+		s.applyHeartBtInt(*s.pendingHeartBtInt)
+	}
 }
 
 func (s *session) onAdmin(msg interface{}) {
@@ -735,6 +932,7 @@ func (s *session) onAdmin(msg interface{}) {
 		s.Connect(s)
 
 	case stopReq:
+		s.awaitLogout = msg.awaitLogout
 		s.Stop(s)
 
 	case waitForInSessionReq:
@@ -742,10 +940,51 @@ func (s *session) onAdmin(msg interface{}) {
 			msg.rep <- s.stateMachine.notifyOnInSessionTime
 		}
 		close(msg.rep)
+
+	case statusReq:
+		msg.rep <- s.status()
+
+	case logonReq:
+		msg.rep <- s.requestLogon()
+
+	case logoutReq:
+		msg.rep <- s.Logout(s, msg.reason)
+
+	case disconnectReq:
+		msg.rep <- s.Disconnect(s)
+
+	case resetReq:
+		msg.rep <- s.Reset(s)
+
+	case refreshReq:
+		msg.rep <- s.refresh()
+
+	case setNextSenderMsgSeqNumReq:
+		msg.rep <- s.SetNextSenderMsgSeqNum(s, msg.next, msg.sequenceReset)
+
+	case setNextTargetMsgSeqNumReq:
+		msg.rep <- s.SetNextTargetMsgSeqNum(s, msg.next, msg.resendRequest)
+
+	case reloadReq:
+		s.reload(msg)
+	}
+}
+
+//markRunning flags the session as running ahead of run, so requests made before the event loop starts are queued
+//rather than rejected
+func (s *session) markRunning() {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	if s.runDone == nil {
+		s.runDone = make(chan struct{})
 	}
 }
 
 func (s *session) run() {
+	s.markRunning()
+	s.awaitLogout = false
+
 	s.Start(s)
 
 	s.stateTimer = internal.NewEventTimer(func() { s.sessionEvent <- internal.NeedHeartbeat })
@@ -756,6 +995,11 @@ func (s *session) run() {
 		s.stateTimer.Stop()
 		s.peerTimer.Stop()
 		ticker.Stop()
+
+		s.runMutex.Lock()
+		close(s.runDone)
+		s.runDone = nil
+		s.runMutex.Unlock()
 	}()
 
 	for !s.Stopped() {
@@ -0,0 +1,101 @@
+package quickfix
+
+import (
+	"net"
+	"time"
+)
+
+//SessionEventListener may be implemented by an Application to observe session lifecycle events, e.g. for alerting.
+//Events are delivered synchronously from the goroutines handling the session, so OnSessionEvent should not block and
+//must be safe for concurrent use.
+type SessionEventListener interface {
+	OnSessionEvent(sessionID SessionID, event SessionEvent)
+}
+
+//SessionEvent is implemented by the events passed to a SessionEventListener. Use a type switch to handle specific
+//events.
+type SessionEvent interface {
+	sessionEvent()
+}
+
+//ConnectingEvent is raised when an initiator attempts to connect.
+type ConnectingEvent struct {
+	Address string
+}
+
+//ConnectFailedEvent is raised when an initiator fails to connect, or the session fails to start on the connection.
+type ConnectFailedEvent struct {
+	Address string
+	Err     error
+}
+
+//TLSHandshakeFailedEvent is raised when an initiator fails the TLS handshake.
+type TLSHandshakeFailedEvent struct {
+	Address string
+	Err     error
+}
+
+//ConnectedEvent is raised when a connection is established for the session.
+type ConnectedEvent struct {
+	RemoteAddr net.Addr
+}
+
+//DisconnectedEvent is raised when the connection of the session is closed.
+type DisconnectedEvent struct{}
+
+//ReconnectingEvent is raised when an initiator schedules the next connection attempt.
+type ReconnectingEvent struct {
+	Interval time.Duration
+}
+
+//ReconnectFailedEvent is raised when an initiator gives up reconnecting after MaxReconnectAttempts.
+type ReconnectFailedEvent struct {
+	Attempts int
+}
+
+//StateChangedEvent is raised on session state transitions, e.g. from "Latent State" to "Logon State".
+type StateChangedEvent struct {
+	From, To string
+}
+
+//SequenceGapEvent is raised when a message is received with a MsgSeqNum higher than expected.
+type SequenceGapEvent struct {
+	Expected, Received int
+}
+
+//ResendRequestSentEvent is raised when a ResendRequest is sent. EndSeqNo 0 requests all messages from BeginSeqNo.
+type ResendRequestSentEvent struct {
+	BeginSeqNo, EndSeqNo int
+}
+
+//ResendRequestReceivedEvent is raised when a ResendRequest is received.
+type ResendRequestReceivedEvent struct {
+	BeginSeqNo, EndSeqNo int
+}
+
+//HeartbeatTimeoutEvent is raised when nothing is received from the counterparty within the heartbeat interval.
+//TestRequestSent is true when a TestRequest is sent in response and false when the TestRequest went unanswered and
+//the session is disconnected.
+type HeartbeatTimeoutEvent struct {
+	TestRequestSent bool
+}
+
+func (ConnectingEvent) sessionEvent()            {}
+func (ConnectFailedEvent) sessionEvent()         {}
+func (TLSHandshakeFailedEvent) sessionEvent()    {}
+func (ConnectedEvent) sessionEvent()             {}
+func (DisconnectedEvent) sessionEvent()          {}
+func (ReconnectingEvent) sessionEvent()          {}
+func (ReconnectFailedEvent) sessionEvent()       {}
+func (StateChangedEvent) sessionEvent()          {}
+func (SequenceGapEvent) sessionEvent()           {}
+func (ResendRequestSentEvent) sessionEvent()     {}
+func (ResendRequestReceivedEvent) sessionEvent() {}
+func (HeartbeatTimeoutEvent) sessionEvent()      {}
+
+//notify passes the event to the session event listener, if any
+func (s *session) notify(event SessionEvent) {
+	if s.eventListener != nil {
+		s.eventListener.OnSessionEvent(s.sessionID, event)
+	}
+}
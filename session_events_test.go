@@ -0,0 +1,147 @@
+package quickfix
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type recordingListener struct {
+	mutex  sync.Mutex
+	events []SessionEvent
+}
+
+func (l *recordingListener) OnSessionEvent(sessionID SessionID, event SessionEvent) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *recordingListener) Events() []SessionEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]SessionEvent(nil), l.events...)
+}
+
+type SessionEventsSuite struct {
+	SessionSuiteRig
+	listener *recordingListener
+}
+
+func TestSessionEventsSuite(t *testing.T) {
+	suite.Run(t, new(SessionEventsSuite))
+}
+
+func (s *SessionEventsSuite) SetupTest() {
+	s.Init()
+	s.listener = &recordingListener{}
+	s.session.eventListener = s.listener
+	s.session.State = inSession{}
+}
+
+func (s *SessionEventsSuite) TestTargetTooHigh() {
+	s.MessageFactory.seqNum = 5
+	s.MockApp.On("ToAdmin")
+	s.fixMsgIn(s.session, s.NewOrderSingle())
+
+	s.Equal([]SessionEvent{
+		SequenceGapEvent{Expected: 1, Received: 6},
+		ResendRequestSentEvent{BeginSeqNo: 1, EndSeqNo: 0},
+		StateChangedEvent{From: "In Session", To: "Resend"},
+	}, s.listener.Events())
+}
+
+func (s *SessionEventsSuite) TestResendRequestReceived() {
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("ToAdmin")
+	s.fixMsgIn(s.session, s.ResendRequest(1))
+
+	s.Equal([]SessionEvent{ResendRequestReceivedEvent{BeginSeqNo: 1, EndSeqNo: 0}}, s.listener.Events())
+}
+
+func (s *SessionEventsSuite) TestHeartbeatTimeout() {
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.PeerTimeout)
+	s.Equal([]SessionEvent{HeartbeatTimeoutEvent{TestRequestSent: true}}, s.listener.Events())
+
+	s.MockApp.On("OnLogout")
+	s.session.Timeout(s.session, internal.PeerTimeout)
+	s.Equal([]SessionEvent{
+		HeartbeatTimeoutEvent{TestRequestSent: true},
+		HeartbeatTimeoutEvent{TestRequestSent: false},
+		DisconnectedEvent{},
+		StateChangedEvent{From: "In Session", To: "Latent State"},
+	}, s.listener.Events())
+}
+
+func (s *SessionEventsSuite) TestNoEventWithoutStateChange() {
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.NeedHeartbeat)
+
+	s.Empty(s.listener.Events())
+}
+
+type sessionEventApp struct {
+	*MockApp
+	*recordingListener
+	failed chan struct{}
+}
+
+func (a sessionEventApp) OnSessionEvent(sessionID SessionID, event SessionEvent) {
+	a.recordingListener.OnSessionEvent(sessionID, event)
+	if _, ok := event.(ReconnectFailedEvent); ok {
+		close(a.failed)
+	}
+}
+
+func TestInitiator_SessionEvents(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	host, port, err := net.SplitHostPort(address)
+	require.Nil(t, err)
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+	settings.GlobalSettings().Set(config.SocketConnectHost, host)
+	settings.GlobalSettings().Set(config.SocketConnectPort, port)
+	settings.GlobalSettings().Set(config.ReconnectInterval, "1")
+	settings.GlobalSettings().Set(config.MaxReconnectAttempts, "1")
+
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIX42)
+	sessionSettings.Set(config.SenderCompID, "initiator")
+	sessionSettings.Set(config.TargetCompID, "events")
+	_, err = settings.AddSession(sessionSettings)
+	require.Nil(t, err)
+
+	app := sessionEventApp{MockApp: &MockApp{}, recordingListener: &recordingListener{}, failed: make(chan struct{})}
+	initiator, err := NewInitiator(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.Nil(t, err)
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	select {
+	case <-app.failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener should be notified after MaxReconnectAttempts")
+	}
+
+	events := app.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, ConnectingEvent{Address: address}, events[0])
+	failed, ok := events[1].(ConnectFailedEvent)
+	require.True(t, ok, "expected ConnectFailedEvent, got %#v", events[1])
+	assert.Equal(t, address, failed.Address)
+	assert.NotNil(t, failed.Err)
+	assert.Equal(t, ReconnectFailedEvent{Attempts: 1}, events[2])
+}
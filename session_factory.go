@@ -59,7 +59,7 @@ func (f sessionFactory) createSession(
 		return
 	}
 	application.OnCreate(session.sessionID)
-	session.log.OnEvent("Created session")
+	session.logEvent(LogLevelInfo, LogCodeSessionCreated, "Created session")
 
 	return
 }
@@ -69,6 +69,31 @@ func (f sessionFactory) newSession(
 	application Application) (s *session, err error) {
 	s = &session{sessionID: sessionID}
 
+	if err = f.configureSession(s, settings); err != nil {
+		return
+	}
+
+	var log Log
+	if log, err = logFactory.CreateSessionLog(s.sessionID); err != nil {
+		return
+	}
+	s.log = newSwappableLog(log)
+
+	if s.store, err = storeFactory.Create(s.sessionID); err != nil {
+		return
+	}
+
+	s.sessionEvent = make(chan internal.Event)
+	s.messageEvent = make(chan bool, 1)
+	s.admin = make(chan interface{})
+	s.connectNow = make(chan struct{}, 1)
+	s.application = application
+	s.eventListener, _ = application.(SessionEventListener)
+	return
+}
+
+//configureSession applies settings to the session configuration, without creating the log and message store
+func (f sessionFactory) configureSession(s *session, settings *SessionSettings) (err error) {
 	var validatorSettings = defaultValidatorSettings
 	if settings.HasSetting(config.ValidateFieldsOutOfOrder) {
 		if validatorSettings.CheckFieldsOutOfOrder, err = settings.BoolSetting(config.ValidateFieldsOutOfOrder); err != nil {
@@ -82,7 +107,7 @@ func (f sessionFactory) newSession(
 		}
 	}
 
-	if sessionID.IsFIXT() {
+	if s.sessionID.IsFIXT() {
 		if s.DefaultApplVerID, err = settings.Setting(config.DefaultApplVerID); err != nil {
 			return
 		}
@@ -186,69 +211,8 @@ func (f sessionFactory) newSession(
 		}
 	}
 
-	if settings.HasSetting(config.StartTime) || settings.HasSetting(config.EndTime) {
-		var startTimeStr, endTimeStr string
-		if startTimeStr, err = settings.Setting(config.StartTime); err != nil {
-			return
-		}
-
-		if endTimeStr, err = settings.Setting(config.EndTime); err != nil {
-			return
-		}
-
-		var start, end internal.TimeOfDay
-		if start, err = internal.ParseTimeOfDay(startTimeStr); err != nil {
-			return
-		}
-
-		if end, err = internal.ParseTimeOfDay(endTimeStr); err != nil {
-			return
-		}
-
-		loc := time.UTC
-		if settings.HasSetting(config.TimeZone) {
-			var locStr string
-			if locStr, err = settings.Setting(config.TimeZone); err != nil {
-				return
-			}
-
-			loc, err = time.LoadLocation(locStr)
-			if err != nil {
-				return
-			}
-		}
-
-		if !settings.HasSetting(config.StartDay) && !settings.HasSetting(config.EndDay) {
-			s.SessionTime = internal.NewTimeRangeInLocation(start, end, loc)
-		} else {
-			var startDayStr, endDayStr string
-			if startDayStr, err = settings.Setting(config.StartDay); err != nil {
-				return
-			}
-
-			if endDayStr, err = settings.Setting(config.EndDay); err != nil {
-				return
-			}
-
-			parseDay := func(setting, dayStr string) (day time.Weekday, err error) {
-				day, ok := dayLookup[dayStr]
-				if !ok {
-					return day, IncorrectFormatForSetting{Setting: setting, Value: dayStr}
-				}
-				return
-			}
-
-			var startDay, endDay time.Weekday
-			if startDay, err = parseDay(config.StartDay, startDayStr); err != nil {
-				return
-			}
-
-			if endDay, err = parseDay(config.EndDay, endDayStr); err != nil {
-				return
-			}
-
-			s.SessionTime = internal.NewWeekRangeInLocation(start, end, startDay, endDay, loc)
-		}
+	if s.SessionTime, err = sessionTimeRange(settings); err != nil {
+		return
 	}
 
 	if settings.HasSetting(config.TimeStampPrecision) {
@@ -290,18 +254,6 @@ func (f sessionFactory) newSession(
 		return
 	}
 
-	if s.log, err = logFactory.CreateSessionLog(s.sessionID); err != nil {
-		return
-	}
-
-	if s.store, err = storeFactory.Create(s.sessionID); err != nil {
-		return
-	}
-
-	s.sessionEvent = make(chan internal.Event)
-	s.messageEvent = make(chan bool, 1)
-	s.admin = make(chan interface{})
-	s.application = application
 	return
 }
 
@@ -334,6 +286,58 @@ func (f sessionFactory) buildInitiatorSettings(session *session, settings *Sessi
 		session.ReconnectInterval = time.Duration(interval) * time.Second
 	}
 
+	if settings.HasSetting(config.ReconnectIntervalMax) {
+
+		interval, err := settings.IntSetting(config.ReconnectIntervalMax)
+		if err != nil {
+			return err
+		}
+
+		if time.Duration(interval)*time.Second < session.ReconnectInterval {
+			return errors.New("ReconnectIntervalMax must not be less than ReconnectInterval")
+		}
+
+		session.ReconnectIntervalMax = time.Duration(interval) * time.Second
+	}
+
+	if settings.HasSetting(config.ReconnectJitter) {
+
+		jitter, err := settings.IntSetting(config.ReconnectJitter)
+		if err != nil {
+			return err
+		}
+
+		if jitter < 0 || jitter > 100 {
+			return errors.New("ReconnectJitter must be between 0 and 100")
+		}
+
+		session.ReconnectJitter = jitter
+	}
+
+	if settings.HasSetting(config.MaxReconnectAttempts) {
+
+		attempts, err := settings.IntSetting(config.MaxReconnectAttempts)
+		if err != nil {
+			return err
+		}
+
+		if attempts < 0 {
+			return errors.New("MaxReconnectAttempts must not be negative")
+		}
+
+		session.MaxReconnectAttempts = attempts
+	}
+
+	if settings.HasSetting(config.SocketConnectFailback) {
+
+		failback, err := settings.BoolSetting(config.SocketConnectFailback)
+		if err != nil {
+			return err
+		}
+
+		session.SocketConnectFailback = failback
+	}
+
 	session.LogoutTimeout = 2 * time.Second
 	if settings.HasSetting(config.LogoutTimeout) {
 
@@ -417,3 +421,74 @@ func (f sessionFactory) buildHeartBtIntSettings(session *session, settings *Sess
 	}
 	return
 }
+
+//sessionTimeRange parses the StartTime, EndTime, StartDay, EndDay and TimeZone settings. The range is nil if the
+//session has no StartTime and EndTime.
+func sessionTimeRange(settings *SessionSettings) (timeRange *internal.TimeRange, err error) {
+	if settings.HasSetting(config.StartTime) || settings.HasSetting(config.EndTime) {
+		var startTimeStr, endTimeStr string
+		if startTimeStr, err = settings.Setting(config.StartTime); err != nil {
+			return
+		}
+
+		if endTimeStr, err = settings.Setting(config.EndTime); err != nil {
+			return
+		}
+
+		var start, end internal.TimeOfDay
+		if start, err = internal.ParseTimeOfDay(startTimeStr); err != nil {
+			return
+		}
+
+		if end, err = internal.ParseTimeOfDay(endTimeStr); err != nil {
+			return
+		}
+
+		loc := time.UTC
+		if settings.HasSetting(config.TimeZone) {
+			var locStr string
+			if locStr, err = settings.Setting(config.TimeZone); err != nil {
+				return
+			}
+
+			loc, err = time.LoadLocation(locStr)
+			if err != nil {
+				return
+			}
+		}
+
+		if !settings.HasSetting(config.StartDay) && !settings.HasSetting(config.EndDay) {
+			timeRange = internal.NewTimeRangeInLocation(start, end, loc)
+		} else {
+			var startDayStr, endDayStr string
+			if startDayStr, err = settings.Setting(config.StartDay); err != nil {
+				return
+			}
+
+			if endDayStr, err = settings.Setting(config.EndDay); err != nil {
+				return
+			}
+
+			parseDay := func(setting, dayStr string) (day time.Weekday, err error) {
+				day, ok := dayLookup[dayStr]
+				if !ok {
+					return day, IncorrectFormatForSetting{Setting: setting, Value: dayStr}
+				}
+				return
+			}
+
+			var startDay, endDay time.Weekday
+			if startDay, err = parseDay(config.StartDay, startDayStr); err != nil {
+				return
+			}
+
+			if endDay, err = parseDay(config.EndDay, endDayStr); err != nil {
+				return
+			}
+
+			timeRange = internal.NewWeekRangeInLocation(start, end, startDay, endDay, loc)
+		}
+	}
+
+	return
+}
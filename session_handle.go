@@ -0,0 +1,127 @@
+package quickfix
+
+import "time"
+
+//SessionStatus is a point in time snapshot of a session.
+type SessionStatus struct {
+	SessionID SessionID
+
+	//State describes the current state of the session state machine, e.g. "In Session"
+	State string
+
+	IsConnected   bool
+	IsLoggedOn    bool
+	IsSessionTime bool
+
+	NextSenderMsgSeqNum int
+	NextTargetMsgSeqNum int
+	CreationTime        time.Time
+}
+
+//Session is a handle for querying and controlling a session managed by an Acceptor or Initiator.
+//Requests are serviced by the session's event loop, so a Session is safe for concurrent use.
+//Requests made while the session is not running fail.
+type Session struct {
+	session *session
+}
+
+//LookupSession returns a handle to the session identified by sessionID.
+func LookupSession(sessionID SessionID) (*Session, bool) {
+	s, ok := lookupSession(sessionID)
+	if !ok {
+		return nil, false
+	}
+
+	return &Session{session: s}, true
+}
+
+//SessionID returns the ID of the session.
+func (h *Session) SessionID() SessionID {
+	return h.session.sessionID
+}
+
+//Status returns a snapshot of the session state and sequence numbers.
+func (h *Session) Status() (SessionStatus, error) {
+	rep := make(chan SessionStatus, 1)
+	if err := h.session.sendAdmin(statusReq{rep}); err != nil {
+		return SessionStatus{}, err
+	}
+
+	return <-rep, nil
+}
+
+//Logon makes an initiator session that is not connected attempt to connect and logon immediately, rather than waiting
+//for the reconnect interval to elapse. Acceptor sessions wait for the counterparty to logon and return an error.
+func (h *Session) Logon() error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(logonReq{rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//Logout sends a Logout with the given reason to the counterparty. Returns an error if the session is not logged on.
+//Initiators will attempt to reconnect after ReconnectInterval.
+func (h *Session) Logout(reason string) error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(logoutReq{reason: reason, rep: rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//Disconnect drops the connection without logging out. Returns an error if the session is not connected.
+func (h *Session) Disconnect() error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(disconnectReq{rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//Reset logs out and disconnects the session if connected, then resets sequence numbers to 1 and drops stored messages.
+func (h *Session) Reset() error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(resetReq{rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//Refresh reloads the session state from the message store.
+func (h *Session) Refresh() error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(refreshReq{rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//SetNextSenderMsgSeqNum sets and persists the next outgoing MsgSeqNum. If sendSequenceReset is true, a SequenceReset
+//in reset mode (GapFillFlag=N) is sent so the counterparty expects the new MsgSeqNum; the session must be logged on and
+//next must not be lower than the current next MsgSeqNum, as a SequenceReset cannot lower what the counterparty expects.
+func (h *Session) SetNextSenderMsgSeqNum(next int, sendSequenceReset bool) error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(setNextSenderMsgSeqNumReq{next: next, sequenceReset: sendSequenceReset, rep: rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
+
+//SetNextTargetMsgSeqNum sets and persists the next expected incoming MsgSeqNum. If sendResendRequest is true and next
+//is lower than the currently expected MsgSeqNum, a ResendRequest is sent for the messages from next onwards; the
+//session must be logged on.
+func (h *Session) SetNextTargetMsgSeqNum(next int, sendResendRequest bool) error {
+	rep := make(chan error, 1)
+	if err := h.session.sendAdmin(setNextTargetMsgSeqNumReq{next: next, resendRequest: sendResendRequest, rep: rep}); err != nil {
+		return err
+	}
+
+	return <-rep
+}
@@ -0,0 +1,82 @@
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/quickfixgo/quickfix/internal"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionHandleTestSuite struct {
+	SessionSuiteRig
+}
+
+func TestSessionHandleTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionHandleTestSuite))
+}
+
+func (suite *SessionHandleTestSuite) SetupTest() {
+	suite.Init()
+	suite.session.admin = make(chan interface{})
+	suite.session.messageEvent = make(chan bool, 1)
+
+	sessionsLock.Lock()
+	defer sessionsLock.Unlock()
+	sessions = make(map[SessionID]*session)
+}
+
+func (suite *SessionHandleTestSuite) TestLookupSession() {
+	_, ok := LookupSession(suite.session.sessionID)
+	suite.False(ok)
+
+	suite.Require().Nil(registerSession(suite.session))
+	handle, ok := LookupSession(suite.session.sessionID)
+	suite.Require().True(ok)
+	suite.Equal(suite.session.sessionID, handle.SessionID())
+}
+
+func (suite *SessionHandleTestSuite) TestNotRunning() {
+	handle := &Session{session: suite.session}
+
+	_, err := handle.Status()
+	suite.Equal(errSessionNotRunning, err)
+	suite.Equal(errSessionNotRunning, handle.Logout(""))
+	suite.Equal(errSessionNotRunning, handle.Disconnect())
+	suite.Equal(errSessionNotRunning, handle.Reset())
+	suite.Equal(errSessionNotRunning, handle.Refresh())
+}
+
+func (suite *SessionHandleTestSuite) TestRunning() {
+	suite.session.sessionEvent = make(chan internal.Event)
+	handle := &Session{session: suite.session}
+
+	done := make(chan interface{})
+	go func() {
+		suite.session.run()
+		close(done)
+	}()
+
+	// status is unavailable until the event loop has started
+	var status SessionStatus
+	var err error
+	for status, err = handle.Status(); err != nil; status, err = handle.Status() {
+	}
+	suite.Equal("Latent State", status.State)
+	suite.False(status.IsConnected)
+	suite.Equal(1, status.NextSenderMsgSeqNum)
+
+	suite.Require().Nil(suite.MockStore.SetNextSenderMsgSeqNum(10))
+	suite.NotNil(handle.Logout(""))
+	suite.NotNil(handle.Disconnect())
+	suite.Nil(handle.Reset())
+
+	status, err = handle.Status()
+	suite.Nil(err)
+	suite.Equal(1, status.NextSenderMsgSeqNum)
+
+	suite.session.stop()
+	<-done
+
+	_, err = handle.Status()
+	suite.Equal(errSessionNotRunning, err)
+}
@@ -1,6 +1,7 @@
 package quickfix
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -50,6 +51,113 @@ func (sm *stateMachine) Stop(session *session) {
 	sm.setState(session, sm.State.Stop(session))
 }
 
+//Logout initiates a logout of a logged on session.
+func (sm *stateMachine) Logout(session *session, reason string) error {
+	if !sm.IsLoggedOn() {
+		return errors.New("Not logged on")
+	}
+
+	if err := session.initiateLogout(reason); err != nil {
+		sm.setState(session, handleStateError(session, err))
+		return err
+	}
+
+	sm.setState(session, logoutState{})
+	return nil
+}
+
+//Disconnect drops the connection without logging out.
+func (sm *stateMachine) Disconnect(session *session) error {
+	if !sm.IsConnected() {
+		return errors.New("Not connected")
+	}
+
+	session.log.OnEvent("Disconnecting")
+	sm.setState(session, latentState{})
+	return nil
+}
+
+//Reset logs out and disconnects a connected session, then resets the message store.
+func (sm *stateMachine) Reset(session *session) error {
+	session.log.OnEvent("Session reset")
+	sm.State.ShutdownNow(session)
+	err := session.dropAndReset()
+
+	if sm.IsConnected() {
+		sm.setState(session, latentState{})
+	}
+
+	return err
+}
+
+//SetNextSenderMsgSeqNum sets the next outgoing MsgSeqNum. If sendSequenceReset is set, a SequenceReset in reset mode
+//is sent so the counterparty expects the new MsgSeqNum. A SequenceReset can only increase the MsgSeqNum the counterparty
+//expects, so the MsgSeqNum is only lowered without one.
+func (sm *stateMachine) SetNextSenderMsgSeqNum(session *session, next int, sendSequenceReset bool) error {
+	if next <= 0 {
+		return errors.New("MsgSeqNum must be greater than zero")
+	}
+
+	if sendSequenceReset && !sm.IsLoggedOn() {
+		return errors.New("Not logged on")
+	}
+
+	session.sendMutex.Lock()
+	if current := session.store.NextSenderMsgSeqNum(); sendSequenceReset && next < current {
+		session.sendMutex.Unlock()
+		return fmt.Errorf("MsgSeqNum %v is lower than the next MsgSeqNum %v, a SequenceReset cannot lower it", next, current)
+	}
+	err := session.store.SetNextSenderMsgSeqNum(next)
+	session.sendMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	session.logEvent(LogLevelInfo, LogCodeMsgSeqNumSet, fmt.Sprintf("Next sender MsgSeqNum set to %v", next),
+		LogAttr{"next_sender_msg_seq_num", next})
+
+	if !sendSequenceReset {
+		return nil
+	}
+
+	return session.sendSequenceReset(next)
+}
+
+//SetNextTargetMsgSeqNum sets the next expected incoming MsgSeqNum. If sendResendRequest is set and next is lower than
+//the currently expected MsgSeqNum, a ResendRequest is sent for the messages between.
+func (sm *stateMachine) SetNextTargetMsgSeqNum(session *session, next int, sendResendRequest bool) error {
+	if next <= 0 {
+		return errors.New("MsgSeqNum must be greater than zero")
+	}
+
+	if sendResendRequest && !sm.IsLoggedOn() {
+		return errors.New("Not logged on")
+	}
+
+	expectedSeqNum := session.store.NextTargetMsgSeqNum()
+	if err := session.store.SetNextTargetMsgSeqNum(next); err != nil {
+		return err
+	}
+	session.logEvent(LogLevelInfo, LogCodeMsgSeqNumSet, fmt.Sprintf("Next target MsgSeqNum set to %v", next),
+		LogAttr{"next_target_msg_seq_num", next})
+
+	if !sendResendRequest || next >= expectedSeqNum {
+		return nil
+	}
+
+	nextState, err := session.sendResendRequest(next, expectedSeqNum-1)
+	if err != nil {
+		sm.setState(session, handleStateError(session, err))
+		return err
+	}
+
+	if currentState, ok := sm.State.(resendState); ok {
+		nextState.messageStash = currentState.messageStash
+	}
+	sm.setState(session, nextState)
+
+	return nil
+}
+
 func (sm *stateMachine) Stopped() bool {
 	return sm.stopped
 }
@@ -70,7 +178,7 @@ func (sm *stateMachine) Incoming(session *session, m fixIn) {
 
 	msg := session.messagePool.Get()
 	if err := ParseMessageWithDataDictionary(msg, m.bytes, session.transportDataDictionary, session.appDataDictionary); err != nil {
-		session.log.OnEventf("Msg Parse Error: %v, %q", err.Error(), m.bytes)
+		session.logEvent(LogLevelError, LogCodeInvalidMessage, fmt.Sprintf("Msg Parse Error: %v, %q", err.Error(), m.bytes), LogAttr{"error", err})
 	} else {
 		msg.ReceiveTime = m.receiveTime
 		sm.fixMsgIn(session, msg)
@@ -107,7 +215,7 @@ func (sm *stateMachine) Timeout(session *session, e internal.Event) {
 func (sm *stateMachine) CheckSessionTime(session *session, now time.Time) {
 	if !session.SessionTime.IsInRange(now) {
 		if sm.IsSessionTime() {
-			session.log.OnEvent("Not in session")
+			session.logEvent(LogLevelInfo, LogCodeNotInSession, "Not in session")
 		}
 
 		sm.State.ShutdownNow(session)
@@ -120,7 +228,7 @@ func (sm *stateMachine) CheckSessionTime(session *session, now time.Time) {
 	}
 
 	if !sm.IsSessionTime() {
-		session.log.OnEvent("In session")
+		session.logEvent(LogLevelInfo, LogCodeInSession, "In session")
 		sm.notifyInSessionTime()
 		sm.setState(session, latentState{})
 	}
@@ -147,6 +255,10 @@ func (sm *stateMachine) setState(session *session, nextState sessionState) {
 		}
 	}
 
+	if sm.State != nil && sm.State.String() != nextState.String() {
+		session.notify(StateChangedEvent{From: sm.State.String(), To: nextState.String()})
+	}
+
 	sm.State = nextState
 }
 
@@ -742,6 +742,237 @@ func (s *SessionSuite) TestOnAdminStop() {
 	s.Stopped()
 }
 
+func (s *SessionSuite) TestOnAdminStatus() {
+	s.session.State = inSession{}
+	s.IncrNextSenderMsgSeqNum()
+
+	rep := make(chan SessionStatus, 1)
+	s.session.onAdmin(statusReq{rep})
+
+	status := <-rep
+	s.Equal(s.session.sessionID, status.SessionID)
+	s.Equal("In Session", status.State)
+	s.True(status.IsConnected)
+	s.True(status.IsLoggedOn)
+	s.True(status.IsSessionTime)
+	s.Equal(2, status.NextSenderMsgSeqNum)
+	s.Equal(1, status.NextTargetMsgSeqNum)
+	s.Equal(s.MockStore.CreationTime(), status.CreationTime)
+}
+
+func (s *SessionSuite) TestOnAdminLogout() {
+	s.session.State = inSession{}
+	s.session.LogoutTimeout = time.Minute
+
+	s.MockApp.On("ToAdmin")
+	rep := make(chan error, 1)
+	s.session.onAdmin(logoutReq{reason: "end of day", rep: rep})
+
+	s.Nil(<-rep)
+	s.MockApp.AssertExpectations(s.T())
+	s.State(logoutState{})
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagText, "end of day", s.MockApp.lastToAdmin.Body)
+}
+
+func (s *SessionSuite) TestOnAdminLogoutNotLoggedOn() {
+	var tests = []sessionState{latentState{}, logonState{}, logoutState{}, notSessionTime{}}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.session.State = test
+
+		rep := make(chan error, 1)
+		s.session.onAdmin(logoutReq{rep: rep})
+
+		s.NotNil(<-rep)
+		s.State(test)
+		s.NoMessageSent()
+	}
+}
+
+func (s *SessionSuite) TestOnAdminDisconnect() {
+	s.session.State = inSession{}
+	s.MockApp.On("OnLogout")
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(disconnectReq{rep})
+
+	s.Nil(<-rep)
+	s.MockApp.AssertExpectations(s.T())
+	s.State(latentState{})
+	s.Disconnected()
+}
+
+func (s *SessionSuite) TestOnAdminDisconnectNotConnected() {
+	s.session.State = latentState{}
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(disconnectReq{rep})
+
+	s.NotNil(<-rep)
+	s.State(latentState{})
+}
+
+func (s *SessionSuite) TestOnAdminReset() {
+	s.session.State = inSession{}
+	s.IncrNextSenderMsgSeqNum()
+	s.IncrNextTargetMsgSeqNum()
+
+	s.MockApp.On("ToAdmin")
+	s.MockApp.On("OnLogout")
+	rep := make(chan error, 1)
+	s.session.onAdmin(resetReq{rep})
+
+	s.Nil(<-rep)
+	s.MockApp.AssertExpectations(s.T())
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.Disconnected()
+	s.State(latentState{})
+	s.ExpectStoreReset()
+}
+
+func (s *SessionSuite) TestOnAdminResetNotConnected() {
+	s.session.State = notSessionTime{}
+	s.IncrNextSenderMsgSeqNum()
+	s.IncrNextTargetMsgSeqNum()
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(resetReq{rep})
+
+	s.Nil(<-rep)
+	s.NoMessageSent()
+	s.State(notSessionTime{})
+	s.ExpectStoreReset()
+}
+
+func (s *SessionSuite) TestOnAdminRefresh() {
+	s.session.State = inSession{}
+	s.MockStore.On("Refresh").Return(nil)
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(refreshReq{rep})
+
+	s.Nil(<-rep)
+	s.MockStore.AssertExpectations(s.T())
+	s.State(inSession{})
+}
+
+func (s *SessionSuite) TestOnAdminSetNextSenderMsgSeqNum() {
+	s.session.State = inSession{}
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextSenderMsgSeqNumReq{next: 100, rep: rep})
+
+	s.Nil(<-rep)
+	s.NoMessageSent()
+	s.NextSenderMsgSeqNum(100)
+	s.State(inSession{})
+}
+
+func (s *SessionSuite) TestOnAdminSetNextSenderMsgSeqNumWithSequenceReset() {
+	s.session.State = inSession{}
+
+	s.MockApp.On("ToAdmin")
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextSenderMsgSeqNumReq{next: 100, sequenceReset: true, rep: rep})
+
+	s.Nil(<-rep)
+	s.MockApp.AssertExpectations(s.T())
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeSequenceReset), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagMsgSeqNum, 100, s.MockApp.lastToAdmin.Header)
+	s.FieldEquals(tagNewSeqNo, 100, s.MockApp.lastToAdmin.Body)
+	s.False(s.MockApp.lastToAdmin.Body.Has(tagGapFillFlag))
+	s.NoMessagePersisted(100)
+	s.NextSenderMsgSeqNum(100)
+}
+
+func (s *SessionSuite) TestOnAdminSetNextSenderMsgSeqNumLowerWithSequenceReset() {
+	s.session.State = inSession{}
+	s.Require().Nil(s.MockStore.SetNextSenderMsgSeqNum(10))
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextSenderMsgSeqNumReq{next: 5, sequenceReset: true, rep: rep})
+
+	s.NotNil(<-rep)
+	s.NoMessageSent()
+	s.NextSenderMsgSeqNum(10)
+	s.State(inSession{})
+
+	s.session.onAdmin(setNextSenderMsgSeqNumReq{next: 5, rep: rep})
+
+	s.Nil(<-rep)
+	s.NoMessageSent()
+	s.NextSenderMsgSeqNum(5)
+}
+
+func (s *SessionSuite) TestOnAdminSetNextSenderMsgSeqNumInvalid() {
+	s.session.State = latentState{}
+
+	var tests = []setNextSenderMsgSeqNumReq{
+		{next: 0},
+		{next: -1},
+		{next: 100, sequenceReset: true},
+	}
+
+	for _, test := range tests {
+		rep := make(chan error, 1)
+		test.rep = rep
+		s.session.onAdmin(test)
+
+		s.NotNil(<-rep)
+		s.NoMessageSent()
+		s.NextSenderMsgSeqNum(1)
+	}
+}
+
+func (s *SessionSuite) TestOnAdminSetNextTargetMsgSeqNum() {
+	s.session.State = inSession{}
+	s.Require().Nil(s.MockStore.SetNextTargetMsgSeqNum(10))
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextTargetMsgSeqNumReq{next: 5, rep: rep})
+
+	s.Nil(<-rep)
+	s.NoMessageSent()
+	s.NextTargetMsgSeqNum(5)
+	s.State(inSession{})
+}
+
+func (s *SessionSuite) TestOnAdminSetNextTargetMsgSeqNumWithResendRequest() {
+	s.session.State = inSession{}
+	s.Require().Nil(s.MockStore.SetNextTargetMsgSeqNum(10))
+
+	s.MockApp.On("ToAdmin")
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextTargetMsgSeqNumReq{next: 5, resendRequest: true, rep: rep})
+
+	s.Nil(<-rep)
+	s.MockApp.AssertExpectations(s.T())
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeResendRequest), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagBeginSeqNo, 5, s.MockApp.lastToAdmin.Body)
+	s.FieldEquals(tagEndSeqNo, 0, s.MockApp.lastToAdmin.Body)
+	s.NextTargetMsgSeqNum(5)
+	s.State(resendState{})
+	s.Equal(9, s.session.State.(resendState).resendRangeEnd)
+}
+
+func (s *SessionSuite) TestOnAdminSetNextTargetMsgSeqNumHigherNoResendRequest() {
+	s.session.State = inSession{}
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(setNextTargetMsgSeqNumReq{next: 5, resendRequest: true, rep: rep})
+
+	s.Nil(<-rep)
+	s.NoMessageSent()
+	s.NextTargetMsgSeqNum(5)
+	s.State(inSession{})
+}
+
 func (s *SessionSuite) TestResetOnDisconnect() {
 	s.IncrNextSenderMsgSeqNum()
 	s.IncrNextTargetMsgSeqNum()
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sync"
 
 	"github.com/quickfixgo/quickfix/config"
 )
@@ -14,6 +15,7 @@ import (
 type Settings struct {
 	globalSettings  *SessionSettings
 	sessionSettings map[SessionID]*SessionSettings
+	sessionsMutex   sync.RWMutex
 }
 
 //Init initializes or resets a Settings instance
@@ -138,6 +140,9 @@ func (s *Settings) GlobalSettings() *SessionSettings {
 
 //SessionSettings return all session settings overlaying globalsettings.
 func (s *Settings) SessionSettings() map[SessionID]*SessionSettings {
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
 	allSessionSettings := make(map[SessionID]*SessionSettings)
 
 	for sessionID, settings := range s.sessionSettings {
@@ -166,6 +171,9 @@ func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, erro
 		return sessionID, errors.New("BeginString must be FIX.4.0 to FIX.4.4 or FIXT.1.1")
 	}
 
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
 	if _, dup := s.sessionSettings[sessionID]; dup {
 		return sessionID, fmt.Errorf("duplicate session configured for %v", sessionID)
 	}
@@ -174,3 +182,72 @@ func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, erro
 
 	return sessionID, nil
 }
+
+//addSessionWithID adds a copy of sessionSettings with the session identifying settings taken from sessionID.
+//Returns an error if the resulting settings, overlaid on the global settings, identify a different session.
+func (s *Settings) addSessionWithID(sessionID SessionID, sessionSettings *SessionSettings) error {
+	settings := NewSessionSettings()
+	if sessionSettings != nil {
+		settings.overlay(sessionSettings)
+	}
+
+	for setting, value := range map[string]string{
+		config.BeginString:      sessionID.BeginString,
+		config.TargetCompID:     sessionID.TargetCompID,
+		config.TargetSubID:      sessionID.TargetSubID,
+		config.TargetLocationID: sessionID.TargetLocationID,
+		config.SenderCompID:     sessionID.SenderCompID,
+		config.SenderSubID:      sessionID.SenderSubID,
+		config.SenderLocationID: sessionID.SenderLocationID,
+		config.SessionQualifier: sessionID.Qualifier,
+	} {
+		if value != "" {
+			settings.Set(setting, value)
+		}
+	}
+
+	id, err := s.AddSession(settings)
+	if err != nil {
+		return err
+	}
+
+	if id != sessionID {
+		s.removeSession(id)
+		return fmt.Errorf("settings for %v identify session %v", sessionID, id)
+	}
+
+	return nil
+}
+
+//setGlobalSettings replaces the global settings with a copy of globalSettings
+func (s *Settings) setGlobalSettings(globalSettings *SessionSettings) {
+	s.lazyInit()
+
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	s.globalSettings = globalSettings.clone()
+}
+
+//setSession replaces the settings of a session with a copy of sessionSettings
+func (s *Settings) setSession(sessionID SessionID, sessionSettings *SessionSettings) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	s.sessionSettings[sessionID] = sessionSettings.clone()
+}
+
+//sessionSettingsFor returns the settings of a session, not overlaid on the global settings
+func (s *Settings) sessionSettingsFor(sessionID SessionID) *SessionSettings {
+	s.sessionsMutex.RLock()
+	defer s.sessionsMutex.RUnlock()
+
+	return s.sessionSettings[sessionID]
+}
+
+func (s *Settings) removeSession(sessionID SessionID) {
+	s.sessionsMutex.Lock()
+	defer s.sessionsMutex.Unlock()
+
+	delete(s.sessionSettings, sessionID)
+}
@@ -0,0 +1,98 @@
+package quickfix
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+//stopContext stops the session, sending a Logout if logged on. Queued messages are sent ahead of the Logout. If ctx
+//can be done, the session waits for the Logout to be acknowledged until then, rather than LogoutTimeout, after which
+//closeConnection is called to force the connection closed.
+func (s *session) stopContext(ctx context.Context, closeConnection func()) error {
+	s.runMutex.Lock()
+	done := s.runDone
+	s.runMutex.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	req := stopReq{awaitLogout: ctx.Done() != nil}
+
+	select {
+	case s.admin <- req:
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+		}
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	closeConnection()
+	s.stopAndWait()
+
+	return errors.Wrap(ctx.Err(), "connection closed before logout completed")
+}
+
+//stopSessions stops sessions concurrently, returning errors for the sessions that could not be stopped gracefully
+func stopSessions(ctx context.Context, sessions []*session, conns *sync.Map) SessionErrors {
+	var wg sync.WaitGroup
+	var errsMutex sync.Mutex
+	errs := make(SessionErrors)
+
+	for _, s := range sessions {
+		wg.Add(1)
+		go func(s *session) {
+			defer wg.Done()
+
+			err := s.stopContext(ctx, func() { closeConnection(conns, s) })
+			if err == nil {
+				return
+			}
+
+			errsMutex.Lock()
+			errs[s.sessionID] = err
+			errsMutex.Unlock()
+		}(s)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+//closeConnection closes the connection of a session tracked in conns
+func closeConnection(conns *sync.Map, s *session) {
+	if conn, ok := conns.Load(s); ok {
+		_ = conn.(net.Conn).Close()
+	}
+}
+
+//closeConnections closes all connections tracked in conns
+func closeConnections(conns *sync.Map) {
+	conns.Range(func(_, conn interface{}) bool {
+		_ = conn.(net.Conn).Close()
+		return true
+	})
+}
+
+//waitContext waits for wg until ctx is done, returning false if ctx is done first
+func waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
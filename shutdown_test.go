@@ -0,0 +1,143 @@
+package quickfix
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShutdownApp() *MockApp {
+	app := &MockApp{}
+	app.On("ToAdmin").Maybe()
+	app.On("FromAdmin").Return(nil).Maybe()
+	app.On("OnLogon").Maybe()
+	app.On("OnLogout").Maybe()
+	return app
+}
+
+func newShutdownAcceptor(t *testing.T, port, targetCompID string) *Acceptor {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, port)
+
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIX42)
+	sessionSettings.Set(config.SenderCompID, "shutdown")
+	sessionSettings.Set(config.TargetCompID, targetCompID)
+	_, err := settings.AddSession(sessionSettings)
+	require.Nil(t, err)
+
+	acceptor, err := NewAcceptor(newShutdownApp(), NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.Nil(t, err)
+	require.Nil(t, acceptor.StartContext(context.Background()))
+	return acceptor
+}
+
+//logon connects to the acceptor as targetCompID and waits for the Logon response
+func logon(t *testing.T, port, targetCompID string) (net.Conn, *parser) {
+	conn, err := net.Dial("tcp", "localhost:"+port)
+	require.Nil(t, err)
+
+	msg := NewMessage()
+	msg.Header.SetField(tagBeginString, FIXString(BeginStringFIX42))
+	msg.Header.SetField(tagMsgType, FIXString(msgTypeLogon))
+	msg.Header.SetField(tagSenderCompID, FIXString(targetCompID))
+	msg.Header.SetField(tagTargetCompID, FIXString("shutdown"))
+	msg.Header.SetField(tagMsgSeqNum, FIXInt(1))
+	msg.Header.SetField(tagSendingTime, FIXUTCTimestamp{Time: time.Now()})
+	msg.Body.SetField(tagEncryptMethod, FIXInt(0))
+	msg.Body.SetField(tagHeartBtInt, FIXInt(30))
+
+	_, err = conn.Write(msg.build())
+	require.Nil(t, err)
+
+	parser := newParser(bufio.NewReader(conn))
+	_, err = parser.ReadMessage()
+	require.Nil(t, err, "expected Logon response")
+
+	return conn, parser
+}
+
+func TestAcceptor_StopContextLogoutAcknowledged(t *testing.T) {
+	acceptor := newShutdownAcceptor(t, "5008", "acked")
+	conn, parser := logon(t, "5008", "acked")
+	defer conn.Close()
+
+	go func() {
+		//reply to the Logout
+		if _, err := parser.ReadMessage(); err != nil {
+			return
+		}
+
+		msg := NewMessage()
+		msg.Header.SetField(tagBeginString, FIXString(BeginStringFIX42))
+		msg.Header.SetField(tagMsgType, FIXString(msgTypeLogout))
+		msg.Header.SetField(tagSenderCompID, FIXString("acked"))
+		msg.Header.SetField(tagTargetCompID, FIXString("shutdown"))
+		msg.Header.SetField(tagMsgSeqNum, FIXInt(2))
+		msg.Header.SetField(tagSendingTime, FIXUTCTimestamp{Time: time.Now()})
+		_, _ = conn.Write(msg.build())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	assert.Nil(t, acceptor.StopContext(ctx))
+	assert.True(t, time.Since(start) < 5*time.Second, "stop should not wait for the deadline once logout is acknowledged")
+}
+
+func TestAcceptor_StopContextForcesClose(t *testing.T) {
+	acceptor := newShutdownAcceptor(t, "5009", "unacked")
+	conn, _ := logon(t, "5009", "unacked")
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err := acceptor.StopContext(ctx)
+	require.NotNil(t, err)
+
+	errs, ok := err.(SessionErrors)
+	require.True(t, ok)
+	assert.Contains(t, errs, SessionID{BeginString: BeginStringFIX42, SenderCompID: "shutdown", TargetCompID: "unacked"})
+
+	//the connection was closed by the acceptor
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	parser := newParser(bufio.NewReader(conn))
+	for {
+		if _, err := parser.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+func TestAcceptor_StopContextNotStarted(t *testing.T) {
+	settings := NewSettings()
+	acceptor, err := NewAcceptor(newShutdownApp(), NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.Nil(t, err)
+
+	assert.Nil(t, acceptor.StopContext(context.Background()))
+}
+
+func TestInitiator_StopContextNotStarted(t *testing.T) {
+	initiator, err := NewInitiator(newShutdownApp(), NewMemoryStoreFactory(), NewSettings(), NewNullLogFactory())
+	require.Nil(t, err)
+
+	assert.Nil(t, initiator.StopContext(context.Background()))
+}
+
+func TestInitiator_StartContextDone(t *testing.T) {
+	initiator, err := NewInitiator(newShutdownApp(), NewMemoryStoreFactory(), NewSettings(), NewNullLogFactory())
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, initiator.StartContext(ctx))
+}
@@ -0,0 +1,144 @@
+package quickfix
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+type sqlLogFactory struct {
+	settings *Settings
+}
+
+type sqlLog struct {
+	sessionID        SessionID
+	db               *sql.DB
+	placeholder      placeholderFunc
+	messagesLogTable string
+	eventLogTable    string
+}
+
+// NewSQLLogFactory returns a sql-based implementation of LogFactory. Messages are written to the messages_log
+// table and events to the event_log table, see the _sql directory for the schema of each database. The tables are
+// created on SQLLogCreateSchema.
+func NewSQLLogFactory(settings *Settings) LogFactory {
+	return sqlLogFactory{settings: settings}
+}
+
+// Create creates a global log. Its session ID columns are empty.
+func (f sqlLogFactory) Create() (Log, error) {
+	return newSQLLogFromSettings(SessionID{}, f.settings.GlobalSettings())
+}
+
+// CreateSessionLog creates a log for the session
+func (f sqlLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %v", sessionID)
+	}
+
+	return newSQLLogFromSettings(sessionID, sessionSettings)
+}
+
+func newSQLLogFromSettings(sessionID SessionID, settings *SessionSettings) (Log, error) {
+	sqlDriver, err := settings.Setting(config.SQLLogDriver)
+	if err != nil {
+		return nil, err
+	}
+	sqlDataSourceName, err := settings.Setting(config.SQLLogDataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	sqlConnMaxLifetime := 0 * time.Second
+	if settings.HasSetting(config.SQLLogConnMaxLifetime) {
+		sqlConnMaxLifetime, err = settings.DurationSetting(config.SQLLogConnMaxLifetime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tablePrefix := ""
+	if settings.HasSetting(config.SQLLogTablePrefix) {
+		if tablePrefix, err = settings.Setting(config.SQLLogTablePrefix); err != nil {
+			return nil, err
+		}
+		if err = validateSQLTablePrefix(tablePrefix); err != nil {
+			return nil, err
+		}
+	}
+	createSchema := false
+	if settings.HasSetting(config.SQLLogCreateSchema) {
+		if createSchema, err = settings.BoolSetting(config.SQLLogCreateSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	return newSQLLog(sessionID, sqlDriver, sqlDataSourceName, sqlConnMaxLifetime, tablePrefix, createSchema)
+}
+
+func newSQLLog(sessionID SessionID, driver string, dataSourceName string, connMaxLifetime time.Duration,
+	tablePrefix string, createSchema bool) (*sqlLog, error) {
+	l := &sqlLog{
+		sessionID:        sessionID,
+		placeholder:      sqlPlaceholder(driver),
+		messagesLogTable: tablePrefix + "messages_log",
+		eventLogTable:    tablePrefix + "event_log",
+	}
+
+	var err error
+	if l.db, err = sql.Open(driver, dataSourceName); err != nil {
+		return nil, err
+	}
+	l.db.SetConnMaxLifetime(connMaxLifetime)
+
+	if err = l.db.Ping(); err != nil { // ensure immediate connection
+		l.db.Close()
+		return nil, err
+	}
+	if createSchema {
+		if err = migrateSQLSchema(l.db, driver, tablePrefix, "log", sqlLogMigrations); err != nil {
+			l.db.Close()
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// insert writes text to table. Errors are dropped, as the Log interface has no way to report them.
+func (l *sqlLog) insert(table string, text string) {
+	s := l.sessionID
+	_, _ = l.db.Exec(sqlString(`INSERT INTO `+table+` (
+			time, beginstring, session_qualifier,
+			sendercompid, sendersubid, senderlocid,
+			targetcompid, targetsubid, targetlocid,
+			text)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, l.placeholder),
+		time.Now().UTC(), s.BeginString, s.Qualifier,
+		s.SenderCompID, s.SenderSubID, s.SenderLocationID,
+		s.TargetCompID, s.TargetSubID, s.TargetLocationID,
+		text)
+}
+
+func (l *sqlLog) OnIncoming(msg []byte) {
+	l.insert(l.messagesLogTable, string(msg))
+}
+
+func (l *sqlLog) OnOutgoing(msg []byte) {
+	l.insert(l.messagesLogTable, string(msg))
+}
+
+func (l *sqlLog) OnEvent(msg string) {
+	l.insert(l.eventLogTable, msg)
+}
+
+func (l *sqlLog) OnEventf(format string, v ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, v...))
+}
+
+// Close closes the log's database connection
+func (l *sqlLog) Close() error {
+	return l.db.Close()
+}
@@ -0,0 +1,150 @@
+package quickfix
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLLogTestSuite struct {
+	suite.Suite
+	sqlLogRootPath string
+	db             *sql.DB
+	sessionID      SessionID
+	logFactory     LogFactory
+}
+
+func (suite *SQLLogTestSuite) SetupTest() {
+	suite.sqlLogRootPath = path.Join(os.TempDir(), fmt.Sprintf("SQLLogTestSuite-%d", os.Getpid()))
+	err := os.MkdirAll(suite.sqlLogRootPath, os.ModePerm)
+	require.Nil(suite.T(), err)
+	sqlDriver := "sqlite3"
+	sqlDsn := path.Join(suite.sqlLogRootPath, fmt.Sprintf("%d.db", time.Now().UnixNano()))
+
+	// create tables
+	suite.db, err = sql.Open(sqlDriver, sqlDsn)
+	require.Nil(suite.T(), err)
+	ddlFnames, err := filepath.Glob(fmt.Sprintf("_sql/%s/*_log_table.sql", sqlDriver))
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), ddlFnames, 2)
+	for _, fname := range ddlFnames {
+		sqlBytes, err := ioutil.ReadFile(fname)
+		require.Nil(suite.T(), err)
+		_, err = suite.db.Exec(string(sqlBytes))
+		require.Nil(suite.T(), err)
+	}
+
+	// create settings
+	suite.sessionID = SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SQLLogDriver=%s
+SQLLogDataSourceName=%s
+SQLLogConnMaxLifetime=14400s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, sqlDriver, sqlDsn, suite.sessionID.BeginString, suite.sessionID.SenderCompID, suite.sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.logFactory = NewSQLLogFactory(settings)
+}
+
+func (suite *SQLLogTestSuite) TearDownTest() {
+	suite.db.Close()
+	os.RemoveAll(suite.sqlLogRootPath)
+}
+
+func (suite *SQLLogTestSuite) rows(table string) (rows []string) {
+	result, err := suite.db.Query(`SELECT beginstring, sendercompid, targetcompid, text FROM ` + table + ` ORDER BY id`)
+	require.Nil(suite.T(), err)
+	defer result.Close()
+
+	for result.Next() {
+		var beginString, senderCompID, targetCompID, text string
+		require.Nil(suite.T(), result.Scan(&beginString, &senderCompID, &targetCompID, &text))
+		rows = append(rows, strings.Join([]string{beginString, senderCompID, targetCompID, text}, "|"))
+	}
+	require.Nil(suite.T(), result.Err())
+
+	return
+}
+
+func (suite *SQLLogTestSuite) TestSessionLog() {
+	log, err := suite.logFactory.CreateSessionLog(suite.sessionID)
+	require.Nil(suite.T(), err)
+	defer log.(io.Closer).Close()
+
+	log.OnIncoming([]byte("incoming"))
+	log.OnOutgoing([]byte("outgoing"))
+	log.OnEvent("event")
+	log.OnEventf("event %d", 2)
+
+	suite.Equal([]string{"FIX.4.4|SENDER|TARGET|incoming", "FIX.4.4|SENDER|TARGET|outgoing"}, suite.rows("messages_log"))
+	suite.Equal([]string{"FIX.4.4|SENDER|TARGET|event", "FIX.4.4|SENDER|TARGET|event 2"}, suite.rows("event_log"))
+
+	var logTime time.Time
+	require.Nil(suite.T(), suite.db.QueryRow(`SELECT time FROM event_log`).Scan(&logTime))
+	suite.WithinDuration(time.Now(), logTime, time.Minute)
+}
+
+func (suite *SQLLogTestSuite) TestGlobalLog() {
+	log, err := suite.logFactory.Create()
+	require.Nil(suite.T(), err)
+	defer log.(io.Closer).Close()
+
+	log.OnEvent("global")
+	suite.Equal([]string{"|||global"}, suite.rows("event_log"))
+}
+
+func (suite *SQLLogTestSuite) TestUnknownSession() {
+	_, err := suite.logFactory.CreateSessionLog(SessionID{BeginString: "FIX.4.4", SenderCompID: "OTHER", TargetCompID: "TARGET"})
+	suite.NotNil(err)
+}
+
+func (suite *SQLLogTestSuite) TestPlaceholders() {
+	suite.Equal("A $1 B $2", sqlString("A ? B ?", sqlPlaceholder("postgres")))
+	suite.Equal("A :1 B :2", sqlString("A ? B ?", sqlPlaceholder("godror")))
+	suite.Equal("A @p1 B @p2", sqlString("A ? B ?", sqlPlaceholder("sqlserver")))
+	suite.Equal("A ? B ?", sqlString("A ? B ?", sqlPlaceholder("mysql")))
+}
+
+func TestSQLLogTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLLogTestSuite))
+}
+
+func TestSQLLogCreateSchema(t *testing.T) {
+	dsn := newSQLSchemaTestDB(t)
+	settings := newSQLSchemaTestSettings(t, dsn, "SQLLogTablePrefix=qf_\nSQLLogCreateSchema=Y")
+
+	for i := 0; i < 2; i++ {
+		log, err := NewSQLLogFactory(settings).CreateSessionLog(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+		require.Nil(t, err)
+		log.OnIncoming([]byte("incoming"))
+		log.OnEvent("event")
+		require.Nil(t, log.(io.Closer).Close())
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+
+	var messages, events int
+	require.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM qf_messages_log`).Scan(&messages))
+	require.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM qf_event_log`).Scan(&events))
+	require.Equal(t, 2, messages)
+	require.Equal(t, 2, events)
+	require.Equal(t, len(sqlLogMigrations), schemaVersion(t, db, "qf_", "log"))
+}
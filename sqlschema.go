@@ -0,0 +1,353 @@
+package quickfix
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sqlSchemaStep is a DDL statement of a migration. A step that creates a table names the table, it is skipped if the
+// table exists, so that tables created by the scripts under _sql are adopted.
+type sqlSchemaStep struct {
+	table string
+	ddl   string
+}
+
+// sqlMigration moves a schema from the previous version to the next, the first migration creates version 1
+type sqlMigration []sqlSchemaStep
+
+// sqlStoreMigrations are the migrations of the sessions and messages tables of the SQL store, in version order.
+// Migrations are only ever appended.
+var sqlStoreMigrations = []sqlMigration{
+	{
+		{table: "sessions", ddl: `CREATE TABLE {prefix}sessions (
+  beginstring {char8} NOT NULL,
+  sendercompid {varchar64} NOT NULL,
+  sendersubid {varchar64} NOT NULL,
+  senderlocid {varchar64} NOT NULL,
+  targetcompid {varchar64} NOT NULL,
+  targetsubid {varchar64} NOT NULL,
+  targetlocid {varchar64} NOT NULL,
+  session_qualifier {varchar64} NOT NULL,
+  creation_time {timestamp} NOT NULL,
+  incoming_seqnum {int} NOT NULL,
+  outgoing_seqnum {int} NOT NULL,
+  PRIMARY KEY (beginstring, sendercompid, sendersubid, senderlocid,
+    targetcompid, targetsubid, targetlocid, session_qualifier)
+)`},
+		{table: "messages", ddl: `CREATE TABLE {prefix}messages (
+  beginstring {char8} NOT NULL,
+  sendercompid {varchar64} NOT NULL,
+  sendersubid {varchar64} NOT NULL,
+  senderlocid {varchar64} NOT NULL,
+  targetcompid {varchar64} NOT NULL,
+  targetsubid {varchar64} NOT NULL,
+  targetlocid {varchar64} NOT NULL,
+  session_qualifier {varchar64} NOT NULL,
+  msgseqnum {int} NOT NULL,
+  message {text} NOT NULL,
+  PRIMARY KEY (beginstring, sendercompid, sendersubid, senderlocid,
+    targetcompid, targetsubid, targetlocid, session_qualifier, msgseqnum)
+)`},
+	},
+}
+
+// sqlLogMigrations are the migrations of the messages_log and event_log tables of the SQL log, in version order.
+// Migrations are only ever appended.
+var sqlLogMigrations = []sqlMigration{
+	{
+		{table: "messages_log", ddl: `CREATE TABLE {prefix}messages_log (
+  id {id},
+  time {timestamp} NOT NULL,
+  beginstring {char8} NOT NULL,
+  sendercompid {varchar64} NOT NULL,
+  sendersubid {varchar64} NOT NULL,
+  senderlocid {varchar64} NOT NULL,
+  targetcompid {varchar64} NOT NULL,
+  targetsubid {varchar64} NOT NULL,
+  targetlocid {varchar64} NOT NULL,
+  session_qualifier {varchar64},
+  text {text} NOT NULL
+)`},
+		{table: "event_log", ddl: `CREATE TABLE {prefix}event_log (
+  id {id},
+  time {timestamp} NOT NULL,
+  beginstring {char8} NOT NULL,
+  sendercompid {varchar64} NOT NULL,
+  sendersubid {varchar64} NOT NULL,
+  senderlocid {varchar64} NOT NULL,
+  targetcompid {varchar64} NOT NULL,
+  targetsubid {varchar64} NOT NULL,
+  targetlocid {varchar64} NOT NULL,
+  session_qualifier {varchar64},
+  text {text} NOT NULL
+)`},
+	},
+}
+
+// sqlSchemaVersionDDL creates the table recording the schema version of each component sharing the table prefix
+const sqlSchemaVersionDDL = `CREATE TABLE {prefix}schema_version (
+  component {varchar64} NOT NULL,
+  version {int} NOT NULL,
+  PRIMARY KEY (component)
+)`
+
+// sqlColumnTypes are the column types of the schema in the DDL of each database, keyed by driver
+var sqlColumnTypes = map[string][]string{
+	"sqlite3": {
+		"{char8}", "CHAR(8)",
+		"{varchar64}", "VARCHAR(64)",
+		"{int}", "INTEGER",
+		"{text}", "TEXT",
+		"{timestamp}", "DATETIME",
+		"{id}", "INTEGER PRIMARY KEY NOT NULL",
+	},
+	"mysql": {
+		"{char8}", "CHAR(8)",
+		"{varchar64}", "VARCHAR(64)",
+		"{int}", "INT",
+		"{text}", "TEXT",
+		"{timestamp}", "DATETIME",
+		"{id}", "INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY",
+	},
+	"postgres": {
+		"{char8}", "CHAR(8)",
+		"{varchar64}", "VARCHAR(64)",
+		"{int}", "INTEGER",
+		"{text}", "TEXT",
+		"{timestamp}", "TIMESTAMP WITH TIME ZONE",
+		"{id}", "SERIAL PRIMARY KEY",
+	},
+	"mssql": {
+		"{char8}", "CHAR(8)",
+		"{varchar64}", "VARCHAR(64)",
+		"{int}", "INT",
+		"{text}", "TEXT",
+		"{timestamp}", "DATETIME",
+		"{id}", "INT NOT NULL IDENTITY PRIMARY KEY",
+	},
+	"oracle": {
+		"{char8}", "VARCHAR2(8)",
+		"{varchar64}", "VARCHAR2(64)",
+		"{int}", "INTEGER",
+		"{text}", "CLOB",
+		"{timestamp}", "TIMESTAMP WITH TIME ZONE",
+		"{id}", "INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+	},
+}
+
+func init() {
+	sqlColumnTypes["pgx"] = sqlColumnTypes["postgres"]
+	sqlColumnTypes["sqlserver"] = sqlColumnTypes["mssql"]
+	for _, driver := range []string{"godror", "goracle", "oci8"} {
+		sqlColumnTypes[driver] = sqlColumnTypes["oracle"]
+	}
+}
+
+// reSQLTablePrefix matches a table name prefix, optionally qualified by a schema, e.g. quickfix.qf_
+var reSQLTablePrefix = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z0-9_]*$`)
+
+func validateSQLTablePrefix(prefix string) error {
+	if !reSQLTablePrefix.MatchString(prefix) {
+		return fmt.Errorf("invalid table prefix: %q", prefix)
+	}
+	return nil
+}
+
+// sqlSchema provisions the tables of a component, the SQL store or log, under a table prefix
+type sqlSchema struct {
+	db          *sql.DB
+	placeholder placeholderFunc
+	ddl         *strings.Replacer
+	prefix      string
+	component   string
+}
+
+// sqlSchemaMigrationWait bounds how long an engine waits for a migration claimed by another engine to complete
+var sqlSchemaMigrationWait = 30 * time.Second
+
+// sqlSchemaMigrationPoll is the interval the version is read at while waiting for another engine
+var sqlSchemaMigrationPoll = 100 * time.Millisecond
+
+// migrateSQLSchema creates the schema version table if missing, then applies the migrations of component that are
+// newer than its recorded version, recording the version after each migration. Engines sharing the database may
+// migrate concurrently: the first migration only creates tables, which every engine may do, later migrations are
+// claimed by one engine while the others wait for it.
+func migrateSQLSchema(db *sql.DB, driver, prefix, component string, migrations []sqlMigration) error {
+	columnTypes, ok := sqlColumnTypes[driver]
+	if !ok {
+		return fmt.Errorf("schema creation is not supported for driver %v", driver)
+	}
+
+	s := sqlSchema{
+		db:          db,
+		placeholder: sqlPlaceholder(driver),
+		ddl:         strings.NewReplacer(append([]string{"{prefix}", prefix}, columnTypes...)...),
+		prefix:      prefix,
+		component:   component,
+	}
+
+	if err := s.createTable("schema_version", sqlSchemaVersionDDL); err != nil {
+		return err
+	}
+
+	version, err := s.version()
+	for ; err == nil; version, err = s.version() {
+		if version < 0 {
+			if version, err = s.awaitMigration(-version); err != nil {
+				return err
+			}
+		}
+		if version > len(migrations) {
+			return fmt.Errorf("%v schema version %d is newer than the latest known version %d", component, version, len(migrations))
+		}
+		if version == len(migrations) {
+			return nil
+		}
+
+		if version > 0 {
+			claimed, err := s.claimMigration(version, version+1)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+		}
+
+		if err = s.migrate(version+1, migrations[version]); err != nil {
+			if version > 0 {
+				s.releaseMigration(version, version+1)
+			}
+			return err
+		}
+		if err = s.setVersion(version, version+1); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// migrate applies the steps of the migration to version
+func (s sqlSchema) migrate(version int, migration sqlMigration) (err error) {
+	for _, step := range migration {
+		if step.table != "" {
+			err = s.createTable(step.table, step.ddl)
+		} else {
+			_, err = s.db.Exec(s.ddl.Replace(step.ddl))
+		}
+		if err != nil {
+			return fmt.Errorf("migrating %v schema to version %d: %v", s.component, version, err)
+		}
+	}
+	return nil
+}
+
+// tableExists probes table with a query returning no rows
+func (s sqlSchema) tableExists(table string) bool {
+	rows, err := s.db.Query(`SELECT 1 FROM ` + s.prefix + table + ` WHERE 1=0`)
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// createTable creates table if it does not exist. A table created concurrently by another engine is not an error.
+func (s sqlSchema) createTable(table, ddl string) error {
+	if s.tableExists(table) {
+		return nil
+	}
+	if _, err := s.db.Exec(s.ddl.Replace(ddl)); err != nil && !s.tableExists(table) {
+		return fmt.Errorf("creating table %v%v: %v", s.prefix, table, err)
+	}
+	return nil
+}
+
+// version returns the recorded schema version of the component, 0 if none is recorded
+func (s sqlSchema) version() (int, error) {
+	var version int
+	err := s.db.QueryRow(sqlString(`SELECT version FROM `+s.prefix+`schema_version WHERE component=?`, s.placeholder),
+		s.component).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("reading %v schema version: %v", s.component, err)
+	}
+	return version, nil
+}
+
+// setVersion records the schema version of the component. A first version recorded concurrently by another engine
+// is not an error, later versions are recorded over the claim of claimMigration.
+func (s sqlSchema) setVersion(from, to int) error {
+	if from == 0 {
+		_, err := s.db.Exec(sqlString(`INSERT INTO `+s.prefix+`schema_version (component, version) VALUES(?, ?)`, s.placeholder),
+			s.component, to)
+		if err != nil {
+			if version, readErr := s.version(); readErr == nil && version >= to {
+				return nil
+			}
+			return fmt.Errorf("recording %v schema version %d: %v", s.component, to, err)
+		}
+		return nil
+	}
+
+	updated, err := s.updateVersion(-to, to)
+	if err != nil {
+		return fmt.Errorf("recording %v schema version %d: %v", s.component, to, err)
+	}
+	if !updated {
+		return fmt.Errorf("recording %v schema version %d: the migration is no longer claimed", s.component, to)
+	}
+	return nil
+}
+
+// updateVersion sets the recorded version to version if it is from, reporting if it was set
+func (s sqlSchema) updateVersion(from, version int) (bool, error) {
+	result, err := s.db.Exec(sqlString(`UPDATE `+s.prefix+`schema_version SET version=? WHERE component=? AND version=?`, s.placeholder),
+		version, s.component, from)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// claimMigration marks the migration from version from to version to as in progress, recorded as the negated version
+// to, so that only this engine applies it. It reports false if another engine changed the version first.
+func (s sqlSchema) claimMigration(from, to int) (bool, error) {
+	claimed, err := s.updateVersion(from, -to)
+	if err != nil {
+		return false, fmt.Errorf("claiming %v schema migration to version %d: %v", s.component, to, err)
+	}
+	return claimed, nil
+}
+
+// releaseMigration restores the version from a failed migration was claimed at, so it can be retried
+func (s sqlSchema) releaseMigration(from, to int) {
+	_, _ = s.updateVersion(-to, from)
+}
+
+// awaitMigration waits for another engine to complete its migration to version to, returning the recorded version
+func (s sqlSchema) awaitMigration(to int) (int, error) {
+	deadline := time.Now().Add(sqlSchemaMigrationWait)
+	for {
+		version, err := s.version()
+		if err != nil || version >= 0 {
+			return version, err
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("%v schema migration to version %d by another engine did not complete within %v, "+
+				"if it was interrupted restore the version in %vschema_version", s.component, to, sqlSchemaMigrationWait, s.prefix)
+		}
+		time.Sleep(sqlSchemaMigrationPoll)
+	}
+}
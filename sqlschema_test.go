@@ -0,0 +1,276 @@
+package quickfix
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// newSQLSchemaTestDB returns the data source name of an empty sqlite database
+func newSQLSchemaTestDB(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "SQLSchemaTest")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return path.Join(dir, "schema.db")
+}
+
+func newSQLSchemaTestSettings(t *testing.T, dsn, extra string) *Settings {
+	settings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SQLStoreDriver=sqlite3
+SQLStoreDataSourceName=%s
+SQLLogDriver=sqlite3
+SQLLogDataSourceName=%s
+%s
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET`, dsn, dsn, extra)))
+	require.Nil(t, err)
+	return settings
+}
+
+func schemaVersion(t *testing.T, db *sql.DB, prefix, component string) (version int) {
+	require.Nil(t, db.QueryRow(`SELECT version FROM `+prefix+`schema_version WHERE component=?`, component).Scan(&version))
+	return
+}
+
+// SQLStoreCreateSchemaTestSuite runs all tests in the MessageStoreTestSuite against a SqlStore creating its tables
+type SQLStoreCreateSchemaTestSuite struct {
+	MessageStoreTestSuite
+}
+
+func (suite *SQLStoreCreateSchemaTestSuite) SetupTest() {
+	settings := newSQLSchemaTestSettings(suite.T(), newSQLSchemaTestDB(suite.T()), "SQLStoreTablePrefix=qf_\nSQLStoreCreateSchema=Y")
+
+	var err error
+	suite.msgStore, err = NewSQLStoreFactory(settings).Create(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+	require.Nil(suite.T(), err)
+}
+
+func (suite *SQLStoreCreateSchemaTestSuite) TearDownTest() {
+	suite.msgStore.Close()
+}
+
+func TestSQLStoreCreateSchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLStoreCreateSchemaTestSuite))
+}
+
+func TestSQLStoreCreateSchema(t *testing.T) {
+	dsn := newSQLSchemaTestDB(t)
+	settings := newSQLSchemaTestSettings(t, dsn, "SQLStoreTablePrefix=qf_\nSQLStoreCreateSchema=Y")
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	store, err := NewSQLStoreFactory(settings).Create(sessionID)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	require.Nil(t, store.Close())
+
+	// the existing schema is kept
+	store, err = NewSQLStoreFactory(settings).Create(sessionID)
+	require.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+	msgs, err := store.GetMessages(1, 1)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("hello")}, msgs)
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+
+	assert.Equal(t, len(sqlStoreMigrations), schemaVersion(t, db, "qf_", "store"))
+	var count int
+	require.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM qf_messages`).Scan(&count))
+	assert.Equal(t, 1, count)
+	_, err = db.Exec(`SELECT 1 FROM sessions`)
+	assert.NotNil(t, err, "tables are only created with the prefix")
+}
+
+func TestSQLStoreCreateSchemaAdoptsTables(t *testing.T) {
+	dsn := newSQLSchemaTestDB(t)
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+
+	ddlFnames, err := filepath.Glob("_sql/sqlite3/*.sql")
+	require.Nil(t, err)
+	for _, fname := range ddlFnames {
+		sqlBytes, err := ioutil.ReadFile(fname)
+		require.Nil(t, err)
+		_, err = db.Exec(string(sqlBytes))
+		require.Nil(t, err)
+	}
+
+	store, err := NewSQLStoreFactory(newSQLSchemaTestSettings(t, dsn, "SQLStoreCreateSchema=Y")).
+		Create(SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+	require.Nil(t, err)
+	defer store.Close()
+
+	assert.Equal(t, len(sqlStoreMigrations), schemaVersion(t, db, "", "store"))
+}
+
+func TestSQLStoreInvalidTablePrefix(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	for _, prefix := range []string{"qf; DROP TABLE x;", "a.b.c", "1schema.qf_"} {
+		_, err := NewSQLStoreFactory(newSQLSchemaTestSettings(t, newSQLSchemaTestDB(t), "SQLStoreTablePrefix="+prefix)).Create(sessionID)
+		assert.NotNil(t, err, prefix)
+	}
+}
+
+func TestMigrateSQLSchema(t *testing.T) {
+	db, err := sql.Open("sqlite3", newSQLSchemaTestDB(t))
+	require.Nil(t, err)
+	defer db.Close()
+
+	migrations := []sqlMigration{
+		{{table: "things", ddl: `CREATE TABLE {prefix}things (id {int} NOT NULL)`}},
+	}
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations))
+	assert.Equal(t, 1, schemaVersion(t, db, "test_", "things"))
+
+	// forward migrations are applied once
+	migrations = append(migrations, sqlMigration{{ddl: `ALTER TABLE {prefix}things ADD COLUMN name {varchar64}`}})
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations))
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations))
+	assert.Equal(t, 2, schemaVersion(t, db, "test_", "things"))
+	_, err = db.Exec(`INSERT INTO test_things (id, name) VALUES(1, 'one')`)
+	assert.Nil(t, err)
+
+	// components are versioned independently
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "other", migrations[:1]))
+	assert.Equal(t, 1, schemaVersion(t, db, "test_", "other"))
+
+	err = migrateSQLSchema(db, "sqlite3", "test_", "things", migrations[:1])
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "newer")
+
+	assert.NotNil(t, migrateSQLSchema(db, "unknown", "test_", "things", migrations))
+}
+
+func TestMigrateSQLSchemaConcurrently(t *testing.T) {
+	dsn := newSQLSchemaTestDB(t) + "?_busy_timeout=10000"
+	migrations := []sqlMigration{
+		{{table: "things", ddl: `CREATE TABLE {prefix}things (id {int} NOT NULL)`}},
+		{{table: "others", ddl: `CREATE TABLE {prefix}others (id {int} NOT NULL)`}},
+		{{ddl: `ALTER TABLE {prefix}things ADD COLUMN name {varchar64}`}},
+	}
+
+	// engines starting together on an empty database all create the schema, the ALTER is applied once
+	const engines = 8
+	start := make(chan struct{})
+	errs := make(chan error, engines)
+	var wg sync.WaitGroup
+	for i := 0; i < engines; i++ {
+		db, err := sql.Open("sqlite3", dsn)
+		require.Nil(t, err)
+		defer db.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- migrateSQLSchema(db, "sqlite3", "test_", "things", migrations)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+	assert.Equal(t, 3, schemaVersion(t, db, "test_", "things"))
+}
+
+func TestSQLSchemaSetVersionRecordedConcurrently(t *testing.T) {
+	db, err := sql.Open("sqlite3", newSQLSchemaTestDB(t))
+	require.Nil(t, err)
+	defer db.Close()
+
+	s := sqlSchema{db: db, placeholder: sqlPlaceholder("sqlite3"), ddl: strings.NewReplacer(append([]string{"{prefix}", "test_"}, sqlColumnTypes["sqlite3"]...)...), prefix: "test_", component: "things"}
+	require.Nil(t, s.createTable("schema_version", sqlSchemaVersionDDL))
+
+	// another engine recorded the first version after this one read none
+	require.Nil(t, s.setVersion(0, 1))
+	require.Nil(t, s.setVersion(0, 1))
+	assert.Equal(t, 1, schemaVersion(t, db, "test_", "things"))
+
+	// later versions are recorded only by the engine that claimed the migration
+	assert.NotNil(t, s.setVersion(1, 2))
+	claimed, err := s.claimMigration(1, 2)
+	require.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = s.claimMigration(1, 2)
+	require.Nil(t, err)
+	assert.False(t, claimed)
+	require.Nil(t, s.setVersion(1, 2))
+	assert.Equal(t, 2, schemaVersion(t, db, "test_", "things"))
+}
+
+func TestMigrateSQLSchemaAwaitsClaimedMigration(t *testing.T) {
+	defer func(wait time.Duration) { sqlSchemaMigrationWait = wait }(sqlSchemaMigrationWait)
+	sqlSchemaMigrationWait = 200 * time.Millisecond
+
+	db, err := sql.Open("sqlite3", newSQLSchemaTestDB(t))
+	require.Nil(t, err)
+	defer db.Close()
+
+	migrations := []sqlMigration{
+		{{table: "things", ddl: `CREATE TABLE {prefix}things (id {int} NOT NULL)`}},
+		{{ddl: `ALTER TABLE {prefix}things ADD COLUMN name {varchar64}`}},
+	}
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations[:1]))
+
+	// another engine claimed the migration to version 2 and was interrupted
+	_, err = db.Exec(`UPDATE test_schema_version SET version=-2 WHERE component='things'`)
+	require.Nil(t, err)
+	err = migrateSQLSchema(db, "sqlite3", "test_", "things", migrations)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "did not complete")
+
+	// the other engine completes the migration while this one waits
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = db.Exec(`ALTER TABLE test_things ADD COLUMN name VARCHAR(64)`)
+		_, _ = db.Exec(`UPDATE test_schema_version SET version=2 WHERE component='things'`)
+	}()
+	require.Nil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations))
+	assert.Equal(t, 2, schemaVersion(t, db, "test_", "things"))
+
+	// a failed migration is released so it can be retried
+	migrations = append(migrations, sqlMigration{{ddl: `ALTER TABLE {prefix}missing ADD COLUMN name {varchar64}`}})
+	assert.NotNil(t, migrateSQLSchema(db, "sqlite3", "test_", "things", migrations))
+	assert.Equal(t, 2, schemaVersion(t, db, "test_", "things"))
+}
+
+func TestSQLColumnTypes(t *testing.T) {
+	for _, driver := range []string{"sqlite3", "mysql", "postgres", "pgx", "sqlserver", "mssql", "godror", "goracle", "oci8"} {
+		replacer := strings.NewReplacer(sqlColumnTypes[driver]...)
+		for _, migrations := range [][]sqlMigration{sqlStoreMigrations, sqlLogMigrations} {
+			for _, migration := range migrations {
+				for _, step := range migration {
+					assert.NotContains(t, replacer.Replace(strings.Replace(step.ddl, "{prefix}", "", -1)), "{", driver)
+				}
+			}
+		}
+	}
+}
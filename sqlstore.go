@@ -22,6 +22,8 @@ type sqlStore struct {
 	sqlConnMaxLifetime time.Duration
 	db                 *sql.DB
 	placeholder        placeholderFunc
+	sessionsTable      string
+	messagesTable      string
 }
 
 type placeholderFunc func(int) string
@@ -44,6 +46,28 @@ func postgresPlaceholder(i int) string {
 	return fmt.Sprintf("$%d", i+1)
 }
 
+func oraclePlaceholder(i int) string {
+	return fmt.Sprintf(":%d", i+1)
+}
+
+func mssqlPlaceholder(i int) string {
+	return fmt.Sprintf("@p%d", i+1)
+}
+
+// sqlPlaceholder returns the placeholder style of driver, nil if the driver uses ?
+func sqlPlaceholder(driver string) placeholderFunc {
+	switch driver {
+	case "postgres", "pgx":
+		return postgresPlaceholder
+	case "godror", "goracle", "oci8":
+		return oraclePlaceholder
+	case "sqlserver", "mssql":
+		return mssqlPlaceholder
+	}
+
+	return nil
+}
+
 // NewSQLStoreFactory returns a sql-based implementation of MessageStoreFactory
 func NewSQLStoreFactory(settings *Settings) MessageStoreFactory {
 	return sqlStoreFactory{settings: settings}
@@ -70,25 +94,43 @@ func (f sqlStoreFactory) Create(sessionID SessionID) (msgStore MessageStore, err
 			return nil, err
 		}
 	}
-	return newSQLStore(sessionID, sqlDriver, sqlDataSourceName, sqlConnMaxLifetime)
+	tablePrefix := ""
+	if sessionSettings.HasSetting(config.SQLStoreTablePrefix) {
+		if tablePrefix, err = sessionSettings.Setting(config.SQLStoreTablePrefix); err != nil {
+			return nil, err
+		}
+		if err = validateSQLTablePrefix(tablePrefix); err != nil {
+			return nil, err
+		}
+	}
+	createSchema := false
+	if sessionSettings.HasSetting(config.SQLStoreCreateSchema) {
+		if createSchema, err = sessionSettings.BoolSetting(config.SQLStoreCreateSchema); err != nil {
+			return nil, err
+		}
+	}
+	return newSQLStore(sessionID, sqlDriver, sqlDataSourceName, sqlConnMaxLifetime, tablePrefix, createSchema)
 }
 
-func newSQLStore(sessionID SessionID, driver string, dataSourceName string, connMaxLifetime time.Duration) (store *sqlStore, err error) {
+// newSQLStore opens the store of sessionID in the tables named with tablePrefix. If createSchema is set, the tables
+// are created if missing and migrated to the latest schema version.
+func newSQLStore(sessionID SessionID, driver string, dataSourceName string, connMaxLifetime time.Duration,
+	tablePrefix string, createSchema bool) (store *sqlStore, err error) {
 	store = &sqlStore{
 		sessionID:          sessionID,
 		cache:              &memoryStore{},
 		sqlDriver:          driver,
 		sqlDataSourceName:  dataSourceName,
 		sqlConnMaxLifetime: connMaxLifetime,
+		sessionsTable:      tablePrefix + "sessions",
+		messagesTable:      tablePrefix + "messages",
 	}
 	if err = store.cache.Reset(); err != nil {
 		err = errors.Wrap(err, "cache reset")
 		return
 	}
 
-	if store.sqlDriver == "postgres" {
-		store.placeholder = postgresPlaceholder
-	}
+	store.placeholder = sqlPlaceholder(store.sqlDriver)
 
 	if store.db, err = sql.Open(store.sqlDriver, store.sqlDataSourceName); err != nil {
 		return nil, err
@@ -96,9 +138,17 @@ func newSQLStore(sessionID SessionID, driver string, dataSourceName string, conn
 	store.db.SetConnMaxLifetime(store.sqlConnMaxLifetime)
 
 	if err = store.db.Ping(); err != nil { // ensure immediate connection
+		store.db.Close()
 		return nil, err
 	}
+	if createSchema {
+		if err = migrateSQLSchema(store.db, driver, tablePrefix, "store", sqlStoreMigrations); err != nil {
+			store.db.Close()
+			return nil, err
+		}
+	}
 	if err = store.populateCache(); err != nil {
+		store.db.Close()
 		return nil, err
 	}
 
@@ -108,7 +158,7 @@ func newSQLStore(sessionID SessionID, driver string, dataSourceName string, conn
 // Reset deletes the store records and sets the seqnums back to 1
 func (store *sqlStore) Reset() error {
 	s := store.sessionID
-	_, err := store.db.Exec(sqlString(`DELETE FROM messages
+	_, err := store.db.Exec(sqlString(`DELETE FROM `+store.messagesTable+`
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
 		AND targetcompid=? AND targetsubid=? AND targetlocid=?`, store.placeholder),
@@ -123,7 +173,7 @@ func (store *sqlStore) Reset() error {
 		return err
 	}
 
-	_, err = store.db.Exec(sqlString(`UPDATE sessions
+	_, err = store.db.Exec(sqlString(`UPDATE `+store.sessionsTable+`
 		SET creation_time=?, incoming_seqnum=?, outgoing_seqnum=?
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
@@ -149,7 +199,7 @@ func (store *sqlStore) populateCache() error {
 	var creationTime time.Time
 	var incomingSeqNum, outgoingSeqNum int
 	row := store.db.QueryRow(sqlString(`SELECT creation_time, incoming_seqnum, outgoing_seqnum
-	  FROM sessions
+	  FROM `+store.sessionsTable+`
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
 		AND targetcompid=? AND targetsubid=? AND targetlocid=?`, store.placeholder),
@@ -177,7 +227,7 @@ func (store *sqlStore) populateCache() error {
 	}
 
 	// session record not found, create it
-	_, err = store.db.Exec(sqlString(`INSERT INTO sessions (
+	_, err = store.db.Exec(sqlString(`INSERT INTO `+store.sessionsTable+` (
 			creation_time, incoming_seqnum, outgoing_seqnum,
 			beginstring, session_qualifier,
 			sendercompid, sendersubid, senderlocid,
@@ -206,7 +256,7 @@ func (store *sqlStore) NextTargetMsgSeqNum() int {
 // SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent
 func (store *sqlStore) SetNextSenderMsgSeqNum(next int) error {
 	s := store.sessionID
-	_, err := store.db.Exec(sqlString(`UPDATE sessions SET outgoing_seqnum = ?
+	_, err := store.db.Exec(sqlString(`UPDATE `+store.sessionsTable+` SET outgoing_seqnum = ?
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
 		AND targetcompid=? AND targetsubid=? AND targetlocid=?`, store.placeholder),
@@ -222,7 +272,7 @@ func (store *sqlStore) SetNextSenderMsgSeqNum(next int) error {
 // SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received
 func (store *sqlStore) SetNextTargetMsgSeqNum(next int) error {
 	s := store.sessionID
-	_, err := store.db.Exec(sqlString(`UPDATE sessions SET incoming_seqnum = ?
+	_, err := store.db.Exec(sqlString(`UPDATE `+store.sessionsTable+` SET incoming_seqnum = ?
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
 		AND targetcompid=? AND targetsubid=? AND targetlocid=?`, store.placeholder),
@@ -256,10 +306,27 @@ func (store *sqlStore) CreationTime() time.Time {
 	return store.cache.CreationTime()
 }
 
+// SetCreationTime sets the creation time of the store
+func (store *sqlStore) SetCreationTime(t time.Time) error {
+	s := store.sessionID
+	_, err := store.db.Exec(sqlString(`UPDATE `+store.sessionsTable+` SET creation_time = ?
+		WHERE beginstring=? AND session_qualifier=?
+		AND sendercompid=? AND sendersubid=? AND senderlocid=?
+		AND targetcompid=? AND targetsubid=? AND targetlocid=?`, store.placeholder),
+		t, s.BeginString, s.Qualifier,
+		s.SenderCompID, s.SenderSubID, s.SenderLocationID,
+		s.TargetCompID, s.TargetSubID, s.TargetLocationID)
+	if err != nil {
+		return err
+	}
+	store.cache.creationTime = t
+	return nil
+}
+
 func (store *sqlStore) SaveMessage(seqNum int, msg []byte) error {
 	s := store.sessionID
 
-	_, err := store.db.Exec(sqlString(`INSERT INTO messages (
+	_, err := store.db.Exec(sqlString(`INSERT INTO `+store.messagesTable+` (
 			msgseqnum, message,
 			beginstring, session_qualifier,
 			sendercompid, sendersubid, senderlocid,
@@ -273,10 +340,16 @@ func (store *sqlStore) SaveMessage(seqNum int, msg []byte) error {
 	return err
 }
 
-func (store *sqlStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+// IterateMessages reads the rows of the range in batches, each batch is read and its rows closed before fn is called,
+// so fn can use the store with a pool of a single connection
+func (store *sqlStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
+	return iterateBatches(beginSeqNum, endSeqNum, store.readMessages, fn)
+}
+
+// readMessages reads the rows of the range
+func (store *sqlStore) readMessages(beginSeqNum, endSeqNum int, add func(seqNum int, msg []byte)) error {
 	s := store.sessionID
-	var msgs [][]byte
-	rows, err := store.db.Query(sqlString(`SELECT message FROM messages
+	rows, err := store.db.Query(sqlString(`SELECT msgseqnum, message FROM `+store.messagesTable+`
 		WHERE beginstring=? AND session_qualifier=?
 		AND sendercompid=? AND sendersubid=? AND senderlocid=?
 		AND targetcompid=? AND targetsubid=? AND targetlocid=?
@@ -287,22 +360,30 @@ func (store *sqlStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error)
 		s.TargetCompID, s.TargetSubID, s.TargetLocationID,
 		beginSeqNum, endSeqNum)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
+		var seqNum int
 		var message string
-		if err := rows.Scan(&message); err != nil {
-			return nil, err
+		if err := rows.Scan(&seqNum, &message); err != nil {
+			return err
 		}
-		msgs = append(msgs, []byte(message))
+		add(seqNum, []byte(message))
 	}
 
-	if err := rows.Err(); err != nil {
+	return rows.Err()
+}
+
+func (store *sqlStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	if err := store.IterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
-
 	return msgs, nil
 }
 
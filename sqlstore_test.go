@@ -65,6 +65,38 @@ func (suite *SQLStoreTestSuite) TestSqlPlaceholderReplacement() {
 	suite.Equal("A $1 B $2 C $3", got)
 }
 
+func (suite *SQLStoreTestSuite) TestIterateMessagesSingleConnection() {
+	defer func(batchSize int) { iterateBatchSize = batchSize }(iterateBatchSize)
+	iterateBatchSize = 2
+
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		suite.Require().Nil(suite.msgStore.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+	}
+
+	// the store is used while iterating, as by a resend, without waiting for the connection of the iteration
+	suite.msgStore.(*sqlStore).db.SetMaxOpenConns(1)
+	var iterated []string
+	done := make(chan error, 1)
+	go func() {
+		done <- suite.msgStore.(MessageIterator).IterateMessages(1, 5, func(seqNum int, msg []byte) error {
+			iterated = append(iterated, string(msg))
+			return suite.msgStore.SaveMessage(seqNum+10, msg)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		suite.Require().Nil(err)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("iterating should not hold the connection while fn runs")
+	}
+	suite.Equal([]string{"msg1", "msg2", "msg3", "msg4", "msg5"}, iterated)
+
+	msgs, err := suite.msgStore.GetMessages(11, 15)
+	suite.Require().Nil(err)
+	suite.Len(msgs, 5)
+}
+
 func (suite *SQLStoreTestSuite) TearDownTest() {
 	suite.msgStore.Close()
 	os.RemoveAll(suite.sqlStoreRootPath)
@@ -28,6 +28,62 @@ type MessageStore interface {
 	Close() error
 }
 
+//MessageIterator may be implemented by a MessageStore to stream the messages of a range in seqnum order, one at a
+//time, rather than loading them all at once as GetMessages does. Resends use it when available. msg is only valid
+//during the call of fn, iteration stops at the first error returned by fn. fn may call the store, e.g. a resend saves
+//and sends messages while iterating, so a store must not hold a resource those calls need, such as a database
+//connection, while fn runs.
+type MessageIterator interface {
+	IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error
+}
+
+//iterateBatchSize is the number of seqnums iterateBatches reads at once
+var iterateBatchSize = 1000
+
+//iterateBatches reads the range in consecutive batches of iterateBatchSize seqnums with readBatch, which passes each
+//message it reads to add. fn is called for the messages of a batch once readBatch has returned, so a store can
+//release its cursor before fn uses the store.
+func iterateBatches(beginSeqNum, endSeqNum int, readBatch func(begin, end int, add func(seqNum int, msg []byte)) error,
+	fn func(seqNum int, msg []byte) error) error {
+	type batchMessage struct {
+		seqNum int
+		msg    []byte
+	}
+
+	for begin := beginSeqNum; begin <= endSeqNum; {
+		end := endSeqNum
+		if endSeqNum-begin >= iterateBatchSize {
+			end = begin + iterateBatchSize - 1
+		}
+
+		var batch []batchMessage
+		if err := readBatch(begin, end, func(seqNum int, msg []byte) {
+			batch = append(batch, batchMessage{seqNum: seqNum, msg: msg})
+		}); err != nil {
+			return err
+		}
+
+		for _, m := range batch {
+			if err := fn(m.seqNum, m.msg); err != nil {
+				return err
+			}
+		}
+
+		if end == endSeqNum {
+			break
+		}
+		begin = end + 1
+	}
+
+	return nil
+}
+
+//CreationTimeSetter is implemented by MessageStores whose creation time can be set, e.g. to carry it over when
+//migrating a session from one store to another
+type CreationTimeSetter interface {
+	SetCreationTime(t time.Time) error
+}
+
 //The MessageStoreFactory interface is used by session to create a session specific message store
 type MessageStoreFactory interface {
 	Create(sessionID SessionID) (MessageStore, error)
@@ -70,6 +126,11 @@ func (store *memoryStore) CreationTime() time.Time {
 	return store.creationTime
 }
 
+func (store *memoryStore) SetCreationTime(t time.Time) error {
+	store.creationTime = t
+	return nil
+}
+
 func (store *memoryStore) Reset() error {
 	store.senderMsgSeqNum = 0
 	store.targetMsgSeqNum = 0
@@ -97,14 +158,24 @@ func (store *memoryStore) SaveMessage(seqNum int, msg []byte) error {
 	return nil
 }
 
-func (store *memoryStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
-	var msgs [][]byte
+func (store *memoryStore) IterateMessages(beginSeqNum, endSeqNum int, fn func(seqNum int, msg []byte) error) error {
 	for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
 		if m, ok := store.messageMap[seqNum]; ok {
-			msgs = append(msgs, m)
+			if err := fn(seqNum, m); err != nil {
+				return err
+			}
 		}
 	}
-	return msgs, nil
+	return nil
+}
+
+func (store *memoryStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	})
+	return msgs, err
 }
 
 type memoryStoreFactory struct{}
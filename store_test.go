@@ -1,6 +1,7 @@
 package quickfix
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -157,6 +158,57 @@ func (suite *MessageStoreTestSuite) TestMessageStore_GetMessages_VariousRanges()
 	}
 }
 
+func (s *MessageStoreTestSuite) TestMessageStore_IterateMessages() {
+	iterator, ok := s.msgStore.(MessageIterator)
+	if !ok {
+		s.T().Skip("store does not implement MessageIterator")
+	}
+
+	// Given the following saved messages
+	s.Require().Nil(s.msgStore.SaveMessage(1, []byte("hello")))
+	s.Require().Nil(s.msgStore.SaveMessage(3, []byte("cruel")))
+	s.Require().Nil(s.msgStore.SaveMessage(4, []byte("world")))
+
+	// When the messages are iterated
+	var seqNums []int
+	var msgs []string
+	err := iterator.IterateMessages(1, 4, func(seqNum int, msg []byte) error {
+		seqNums = append(seqNums, seqNum)
+		msgs = append(msgs, string(msg))
+		return nil
+	})
+
+	// Then they should be in seqnum order
+	s.Require().Nil(err)
+	s.Equal([]int{1, 3, 4}, seqNums)
+	s.Equal([]string{"hello", "cruel", "world"}, msgs)
+
+	// When fn fails, the iteration should stop with its error
+	stopErr := errors.New("stop")
+	calls := 0
+	err = iterator.IterateMessages(1, 4, func(seqNum int, msg []byte) error {
+		calls++
+		return stopErr
+	})
+	s.Equal(stopErr, err)
+	s.Equal(1, calls)
+}
+
+func (s *MessageStoreTestSuite) TestMessageStore_SetCreationTime() {
+	setter, ok := s.msgStore.(CreationTimeSetter)
+	s.Require().True(ok)
+
+	creationTime := time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)
+	s.Require().Nil(setter.SetCreationTime(creationTime))
+	s.True(creationTime.Equal(s.msgStore.CreationTime()))
+
+	// When the store is refreshed from its backing store
+	s.Require().Nil(s.msgStore.Refresh())
+
+	// Then the creation time should still be
+	s.True(creationTime.Equal(s.msgStore.CreationTime()))
+}
+
 func (s *MessageStoreTestSuite) TestMessageStore_CreationTime() {
 	s.False(s.msgStore.CreationTime().IsZero())
 
@@ -0,0 +1,103 @@
+package quickfix
+
+import "time"
+
+//LogLevel is the severity of a LogEvent
+type LogLevel int
+
+//LogLevel values, in increasing severity
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	}
+
+	return "unknown"
+}
+
+//Stable codes of the events logged by the engine. Events without a specific code are logged with LogCodeEvent.
+const (
+	LogCodeEvent                 = "event"
+	LogCodeError                 = "error"
+	LogCodeIncoming              = "incoming"
+	LogCodeOutgoing              = "outgoing"
+	LogCodeSessionCreated        = "session_created"
+	LogCodeConnecting            = "connecting"
+	LogCodeConnectFailed         = "connect_failed"
+	LogCodeReconnecting          = "reconnecting"
+	LogCodeReconnectFailed       = "reconnect_failed"
+	LogCodeFailback              = "failback"
+	LogCodeDisconnected          = "disconnected"
+	LogCodeInSession             = "in_session"
+	LogCodeNotInSession          = "not_in_session"
+	LogCodeSequenceGap           = "sequence_gap"
+	LogCodeResendRequestSent     = "resend_request_sent"
+	LogCodeResendRequestReceived = "resend_request_received"
+	LogCodeSequenceResetSent     = "sequence_reset_sent"
+	LogCodeSequenceResetReceived = "sequence_reset_received"
+	LogCodeMsgSeqNumSet          = "msg_seq_num_set"
+	LogCodeTestRequestSent       = "test_request_sent"
+	LogCodeHeartbeatTimeout      = "heartbeat_timeout"
+	LogCodeLogonTimeout          = "logon_timeout"
+	LogCodeLogoutTimeout         = "logout_timeout"
+	LogCodeMessageRejected       = "message_rejected"
+	LogCodeInvalidMessage        = "invalid_message"
+	LogCodeSettingsReloaded      = "settings_reloaded"
+	LogCodeConnectionTerminated  = "connection_terminated"
+	LogCodeLogFailed             = "log_failed"
+)
+
+//LogAttr is a key/value attribute of a LogEvent
+type LogAttr struct {
+	Key   string
+	Value interface{}
+}
+
+//LogEvent is a structured event with a severity, a stable code and attributes such as seqnums, addresses and errors.
+//Message is the text passed to OnEvent for logs that are not a StructuredLog.
+type LogEvent struct {
+	Time      time.Time
+	Level     LogLevel
+	Code      string
+	SessionID SessionID
+	Message   string
+	Attrs     []LogAttr
+}
+
+//StructuredLog may be implemented by a Log to receive events as LogEvent rather than through OnEvent
+type StructuredLog interface {
+	Log
+
+	OnLogEvent(event LogEvent)
+}
+
+//logEvent passes event to log, falling back on OnEvent if log is not a StructuredLog
+func logEvent(log Log, event LogEvent) {
+	if structured, ok := log.(StructuredLog); ok {
+		if event.Time.IsZero() {
+			event.Time = time.Now().UTC()
+		}
+		structured.OnLogEvent(event)
+		return
+	}
+
+	log.OnEvent(event.Message)
+}
+
+//logEvent logs a structured event for the session
+func (s *session) logEvent(level LogLevel, code string, message string, attrs ...LogAttr) {
+	logEvent(s.log, LogEvent{Level: level, Code: code, SessionID: s.sessionID, Message: message, Attrs: attrs})
+}